@@ -14,6 +14,7 @@ import (
 // Configuration represents the application configuration.
 type Configuration struct {
 	ServerCfg  ServerConf `json:"server_cfg" toml:"server_cfg"`
+	JobsCfg    JobsConf   `json:"jobs_cfg" toml:"jobs_cfg"`
 	UseCORS    bool       `json:"use_cors" toml:"use_cors"`
 	DocsFolder string     `json:"docs_folder" toml:"docs_folder"`
 }
@@ -26,6 +27,17 @@ type ServerConf struct {
 	StartupMessage bool   `json:"startup_message" toml:"startup_message"`
 }
 
+// JobsConf contains settings of asynchronous optimization jobs.
+// Zero values fall back to defaults: 2 workers, queue of 100 jobs, results are kept for 3600 seconds.
+type JobsConf struct {
+	// Number of jobs which run concurrently
+	Workers int `json:"workers" toml:"workers"`
+	// Max number of jobs waiting for a free worker
+	QueueSize int `json:"queue_size" toml:"queue_size"`
+	// Time in seconds to keep finished jobs (with results)
+	ResultTTL int `json:"result_ttl" toml:"result_ttl"`
+}
+
 // PrepareConfiguration initializes the configuration from a file specified by the command line flag.
 func PrepareConfiguration() (*Configuration, error) {
 	confName := flag.String("conf", "", "Config file path")
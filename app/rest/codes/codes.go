@@ -76,3 +76,10 @@ type Error422 struct {
 	// Error indicates the error message for unprocessable entity errors.
 	Error string `json:"Error" example:"Unprocessable Entity"`
 }
+
+// Error404 Not Found
+// swagger:model
+type Error404 struct {
+	// Error indicates the error message for not found errors.
+	Error string `json:"Error" example:"Not Found"`
+}
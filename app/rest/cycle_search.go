@@ -0,0 +1,192 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/LdDl/greenwave"
+	"github.com/LdDl/greenwave/app/rest/dto"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// maxCycleCandidates is the largest number of candidate cycle lengths in a single search: offsets are optimized for each of them
+const maxCycleCandidates = 200
+
+// CycleSearchRequest represents the request structure for common cycle length search requests.
+// swagger:model
+type CycleSearchRequest struct {
+	// Corridor and optimizer settings used for each candidate cycle length. Green split optimization is not supported:
+	// signal durations are defined by the candidate cycle length and cycle scaling
+	OptimizeRequest
+	// Shortest candidate cycle length in seconds. Default is 60
+	MinCycle float64 `json:"min_cycle"`
+	// Longest candidate cycle length in seconds. Default is 150
+	MaxCycle float64 `json:"max_cycle"`
+	// Difference between consecutive candidates in seconds. Default is 5. Range should contain no more than 200 candidates
+	CycleStep float64 `json:"cycle_step"`
+	// How signal durations are adjusted to the candidate: "proportional" (default) or "bounds" (within min/max durations)
+	CycleScaling string `json:"cycle_scaling" example:"proportional"`
+	// Indices of junctions which run at half of the common cycle (double cycling)
+	HalfCycleJunctions []int `json:"half_cycle_junctions"`
+}
+
+// CycleSearchResponse represents the response structure for common cycle length search requests.
+// swagger:model
+type CycleSearchResponse struct {
+	// Recommended common cycle length in seconds
+	RecommendedCycle float64 `json:"recommended_cycle"`
+	// Plan for the recommended cycle length
+	Recommended dto.CycleCandidateDTO `json:"recommended"`
+	// Bandwidth-vs-cycle curve: every feasible candidate in ascending order of cycle length
+	Curve []dto.CycleCandidateDTO `json:"curve"`
+	// Seed of the random source actually used. Pass it in the request to reproduce the result
+	Seed uint64 `json:"seed"`
+}
+
+// RequestCycleSearch return recommended common cycle length with the bandwidth-vs-cycle curve.
+// @Summary Request common cycle length search
+// @Description Scales junction cycles across the candidate range, optimizes offsets for each candidate and recommends the common cycle length
+// @Tags Optimize
+// @Produce json
+// @Param POST-body body rest.CycleSearchRequest true "Traffic lights configuration and candidate range"
+// @Success 200 {object} rest.CycleSearchResponse
+// @Failure 400 {object} codes.Error400
+// @Failure 500 {object} codes.Error500
+// @Router /api/greenwave/optimize/cycle [POST]
+func RequestCycleSearch() func(ctx echo.Context) error {
+	return func(ctx echo.Context) error {
+		bodyBytes, err := io.ReadAll(ctx.Request().Body)
+		if err != nil {
+			errReason := "Can't read body"
+			log.Error().Err(err).Str("scope", "api").Str("method", ctx.Request().Method).Str("route", ctx.Request().URL.Path).RawJSON("req_body", bodyBytes).Msg(errReason)
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+		requestData := CycleSearchRequest{}
+		err = json.Unmarshal(bodyBytes, &requestData)
+		if err != nil {
+			errReason := "Can't unmarshal request data"
+			log.Error().Err(err).Str("scope", "api").Str("method", ctx.Request().Method).Str("route", ctx.Request().URL.Path).RawJSON("req_body", bodyBytes).Msg(errReason)
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+
+		// Validate input
+		if len(requestData.Junctions) < 2 {
+			return ctx.JSON(400, echo.Map{
+				"Error": "At least 2 junctions are required",
+			})
+		}
+		if requestData.DesiredSpeedKmh <= 0 {
+			return ctx.JSON(400, echo.Map{
+				"Error": "Desired speed must be greater than 0",
+			})
+		}
+		if requestData.GreenSplit != nil {
+			return ctx.JSON(400, echo.Map{
+				"Error": "Green split optimization is not supported by cycle length search",
+			})
+		}
+
+		// Convert DTOs to domain objects
+		junctions, err := junctionsFromDTO(requestData.Junctions)
+		if err != nil {
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+
+		extractOptions, settingsOptions, seed, err := prepareOptimizerOptions(junctions, requestData.OptimizeRequest)
+		if err != nil {
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+		searchOptions, err := prepareCycleSearchOptions(len(junctions), requestData)
+		if err != nil {
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+		searchOptions = append(searchOptions, greenwave.WithCycleSearchExtractOptions(extractOptions...))
+		if requestData.TwoWay {
+			searchOptions = append(searchOptions, greenwave.WithCycleSearchTwoWay(inboundSpeed(requestData.DesiredSpeedKmh, requestData.InboundSpeedKmh)))
+		}
+
+		// Optimizer is validated against scaled junctions: e.g. MAXBAND requires common cycle which is reached by scaling only
+		newOptimizer := func(scaled []*greenwave.Junction) (greenwave.Optimizer, error) {
+			return createOptimizer(requestData.OptimizerType, scaled, requestData.DesiredSpeedKmh, requestData.OptimizerParams, settingsOptions...)
+		}
+
+		// Context-aware optimizers stop as soon as the client disconnects
+		result, err := greenwave.SearchCycleLengthContext(ctx.Request().Context(), junctions, requestData.DesiredSpeedKmh, newOptimizer, searchOptions...)
+		if ctx.Request().Context().Err() != nil {
+			log.Warn().Str("scope", "api").Str("method", ctx.Request().Method).Str("route", ctx.Request().URL.Path).Msg("Cycle length search has been cancelled")
+			return nil // Client has gone, nobody to respond to
+		}
+		if err != nil {
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+
+		response := CycleSearchResponse{
+			RecommendedCycle: result.Recommended.Cycle,
+			Recommended:      dto.CycleCandidateToDTO(result.Recommended),
+			Curve:            make([]dto.CycleCandidateDTO, len(result.Curve)),
+			Seed:             seed,
+		}
+		for i, candidate := range result.Curve {
+			response.Curve[i] = dto.CycleCandidateToDTO(candidate)
+		}
+		return ctx.JSON(200, response)
+	}
+}
+
+// prepareCycleSearchOptions converts candidate range related request fields to cycle search options
+func prepareCycleSearchOptions(junctionsNum int, requestData CycleSearchRequest) ([]func(*greenwave.CycleSearchSettings), error) {
+	minCycle, maxCycle, step := 60.0, 150.0, 5.0
+	if requestData.MinCycle != 0 {
+		minCycle = requestData.MinCycle
+	}
+	if requestData.MaxCycle != 0 {
+		maxCycle = requestData.MaxCycle
+	}
+	if requestData.CycleStep != 0 {
+		step = requestData.CycleStep
+	}
+	if minCycle <= 0 || maxCycle < minCycle || step <= 0 {
+		return nil, fmt.Errorf("min cycle must be positive, max cycle must not be less than min cycle and cycle step must be positive")
+	}
+	if candidatesNum := math.Floor((maxCycle-minCycle)/step+1e-9) + 1; candidatesNum > maxCycleCandidates {
+		return nil, fmt.Errorf("too many candidate cycle lengths: %.0f, expected no more than %d (increase cycle step or narrow the range)", candidatesNum, maxCycleCandidates)
+	}
+	var scaling greenwave.CycleScalingMode
+	switch strings.ToLower(requestData.CycleScaling) {
+	case "", "proportional":
+		scaling = greenwave.CYCLE_SCALING_PROPORTIONAL
+	case "bounds":
+		scaling = greenwave.CYCLE_SCALING_BOUNDS
+	default:
+		return nil, fmt.Errorf("unsupported cycle scaling: %s", requestData.CycleScaling)
+	}
+	for _, idx := range requestData.HalfCycleJunctions {
+		if idx < 0 || idx >= junctionsNum {
+			return nil, fmt.Errorf("half cycle junction index %d is out of range", idx)
+		}
+	}
+	if len(requestData.HalfCycleJunctions) > 0 && strings.ToLower(requestData.OptimizerType) == "maxband" {
+		return nil, fmt.Errorf("half cycle junctions are not supported by maxband optimizer: it requires common cycle length")
+	}
+	return []func(*greenwave.CycleSearchSettings){
+		greenwave.WithCycleRange(minCycle, maxCycle, step),
+		greenwave.WithCycleScaling(scaling),
+		greenwave.WithHalfCycleJunctions(requestData.HalfCycleJunctions...),
+	}, nil
+}
@@ -11,11 +11,91 @@ type JunctionDTO struct {
 	// Cycle is a list of phases that define the traffic light cycle for this junction
 	Cycle []PhaseDTO `json:"cycle"`
 	// Total duration of the cycle in seconds
-	TotalDuration int `json:"total_duration"`
-	// Offset of the cycle
-	Offset int `json:"offset"`
+	TotalDuration float64 `json:"total_duration"`
+	// Offset of the cycle in seconds
+	Offset float64 `json:"offset"`
 	// Location of the junction
 	Point PointDTO `json:"point"`
+	// Optional effective green policy of the junction. Takes precedence over the corridor one
+	EffectiveGreen *EffectiveGreenDTO `json:"effective_green,omitempty"`
+	// Optional coordinated movement of the junction: phases which serve the through movement
+	Movement *MovementDTO `json:"movement,omitempty"`
+	// Optional named signal groups with their own color timelines across the cycle
+	SignalGroups []SignalGroupDTO `json:"signal_groups,omitempty"`
+	// Optional restriction of offsets which optimizers could choose for the junction
+	OffsetConstraint *OffsetConstraintDTO `json:"offset_constraint,omitempty"`
+}
+
+// OffsetConstraintDTO represents an offset constraint of the junction for API communication.
+// Offsets are in seconds in the same time frame as junction offsets and are taken modulo the junction cycle.
+// swagger:model
+type OffsetConstraintDTO struct {
+	// Type of the constraint: "fixed" (offset must not change), "window" (offset must be within the window) or "discrete" (offset must be one of the allowed values)
+	Type string `json:"type" example:"window"`
+	// Fixed offset. If not provided then offset of the junction is used. Fixed constraint only
+	Offset *float64 `json:"offset,omitempty"`
+	// Lower bound of the window, e.g. -10 for "not earlier than 10 seconds before the cycle start". Window constraint only
+	Min *float64 `json:"min,omitempty"`
+	// Upper bound of the window. Window constraint only
+	Max *float64 `json:"max,omitempty"`
+	// Allowed deviation from offset of the junction, e.g. 10 for "within ±10 seconds of the current plan". Alternative to min/max. Window constraint only
+	Tolerance float64 `json:"tolerance,omitempty"`
+	// Allowed offsets. Discrete constraint only
+	Offsets []float64 `json:"offsets,omitempty"`
+}
+
+// SignalGroupDTO represents a signal group for API communication.
+// Represents a named group of signals (e.g. "NB through") with its own color timeline across the cycle.
+// swagger:model
+type SignalGroupDTO struct {
+	// Name of the signal group
+	Name string `json:"name"`
+	// Color timeline of the group from the beginning of the cycle. Total duration must match the junction cycle
+	Signals []SignalDTO `json:"signals"`
+	// Total duration of the timeline in seconds, calculated from the signals
+	TotalSeconds float64 `json:"total_seconds"`
+}
+
+// GreenSplitDTO represents green split optimization settings for API communication.
+// Signal durations within their min/max bounds become decision variables alongside offsets.
+// swagger:model
+type GreenSplitDTO struct {
+	// Mode of the optimization: "fixed_cycle" (default) rebalances durations keeping the cycle length, "free_cycle" lets the common cycle length of junctions change within bounds
+	Mode string `json:"mode" example:"fixed_cycle"`
+}
+
+// CoordinatedGroupsDTO represents selection of coordinated signal groups for API communication.
+// Each list is indexed by junction position; empty name means the phases timeline of the junction.
+// swagger:model
+type CoordinatedGroupsDTO struct {
+	// Names of coordinated signal groups for the outbound direction
+	Outbound []string `json:"outbound"`
+	// Names of coordinated signal groups for the inbound direction
+	Inbound []string `json:"inbound"`
+}
+
+// MovementDTO represents a coordinated movement for API communication.
+// Declares which phases of the junction serve the coordinated (through) movement.
+// swagger:model
+type MovementDTO struct {
+	// Indices of phases (positions in cycle) which serve the movement
+	Phases []int `json:"phases"`
+	// Maximum gap in seconds between consecutive greens of the movement which is still merged into a single interval
+	MaxGap float64 `json:"max_gap"`
+}
+
+// EffectiveGreenDTO represents an effective green policy for API communication.
+// Defines which part of the signal timeline is actually usable by vehicles.
+// swagger:model
+type EffectiveGreenDTO struct {
+	// Time in seconds lost at the beginning of green due vehicles start-up
+	StartupLostTime float64 `json:"startup_lost_time"`
+	// Time in seconds of the following YELLOW signal which is still used by drivers
+	YellowExtension float64 `json:"yellow_extension"`
+	// Enables counting REDYELLOW signal as usable time
+	UseRedYellow bool `json:"use_red_yellow"`
+	// Enables counting GREENRIGHT signal as usable time
+	UseGreenRight bool `json:"use_green_right"`
 }
 
 // PhaseDTO represents a phase for API communication.
@@ -27,7 +107,7 @@ type PhaseDTO struct {
 	// List of signals that define the phase
 	Signals []SignalDTO `json:"signals"`
 	// Total duration of the phase in seconds, calculated from the signals
-	TotalSeconds int `json:"total_seconds"`
+	TotalSeconds float64 `json:"total_seconds"`
 }
 
 // SignalDTO represents a signal for API communication.
@@ -35,12 +115,12 @@ type PhaseDTO struct {
 // Also it includes minimum and maximum duration threshold for the signal which could be usefull during optimizations of traffic light timings.
 // swagger:model
 type SignalDTO struct {
-	// Duration is the duration of the signal in seconds.
-	Duration int `json:"duration"`
+	// Duration is the duration of the signal in seconds. Could be fractional (e.g. 12.5)
+	Duration float64 `json:"duration"`
 	// MinDuration is the minimum duration for the signal in seconds. Could be used during optimizations in further researchs.
-	MinDuration *int `json:"min_duration"`
+	MinDuration *float64 `json:"min_duration"`
 	// MaxDuration is the maximum duration for the signal in seconds. Could be used during optimizations in further researchs.
-	MaxDuration *int `json:"max_duration"`
+	MaxDuration *float64 `json:"max_duration"`
 	// Color is the color of the signal
 	Color string `json:"color"`
 }
@@ -49,8 +129,57 @@ type SignalDTO struct {
 // Point in 2D space.
 // swagger:model
 type PointDTO struct {
+	// X coordinate in meters for planar CRS or longitude in degrees for WGS84
 	X float64 `json:"x"`
+	// Y coordinate in meters for planar CRS or latitude in degrees for WGS84
 	Y float64 `json:"y"`
+	// Coordinate reference system: "planar" (default) or "wgs84"
+	CRS string `json:"crs" example:"planar"`
+}
+
+// SegmentDTO represents a segment for API communication.
+// Represents a corridor link between two consecutive junctions.
+// swagger:model
+type SegmentDTO struct {
+	// Distance between junctions in meters. If it is not provided then length of the geometry or straight line distance between junctions points is used
+	DistanceMeters float64 `json:"distance_meters"`
+	// Optional road polyline from the first junction to the second one
+	Geometry []PointDTO `json:"geometry,omitempty"`
+	// Optional road polyline as GeoJSON LineString (WGS84). Used only if geometry is not provided
+	GeoJSON *LineStringDTO `json:"geojson,omitempty"`
+	// Design speed on the segment in km/h. If it is not provided then desired speed is used
+	SpeedKmh float64 `json:"speed_kmh"`
+	// Explicit travel time in seconds. If it is provided then it takes precedence over distance and speed
+	TravelTimeSeconds float64 `json:"travel_time_seconds"`
+	// Minimum allowed speed on the segment in km/h. Zero means no limit
+	MinSpeedKmh float64 `json:"min_speed_kmh"`
+	// Maximum allowed speed on the segment in km/h. Zero means no limit
+	MaxSpeedKmh float64 `json:"max_speed_kmh"`
+}
+
+// SpeedRangeDTO represents a speed range for API communication.
+// Either explicit bounds (min_speed_kmh and max_speed_kmh) or normal speed distribution (mean_speed_kmh, std_dev_kmh and coverage) should be provided.
+// swagger:model
+type SpeedRangeDTO struct {
+	// Lower bound of the speed range in km/h
+	MinSpeedKmh float64 `json:"min_speed_kmh"`
+	// Upper bound of the speed range in km/h
+	MaxSpeedKmh float64 `json:"max_speed_kmh"`
+	// Mean speed in km/h of normal speed distribution. Used if bounds are not provided
+	MeanSpeedKmh float64 `json:"mean_speed_kmh"`
+	// Standard deviation in km/h of normal speed distribution
+	StdDevKmh float64 `json:"std_dev_kmh"`
+	// Share of vehicles (0; 1) which speeds should be covered by the range. Default is 0.85
+	Coverage float64 `json:"coverage"`
+}
+
+// LineStringDTO represents a GeoJSON LineString geometry for API communication.
+// swagger:model
+type LineStringDTO struct {
+	// GeoJSON type. Must be "LineString"
+	Type string `json:"type" example:"LineString"`
+	// List of [longitude, latitude] positions
+	Coordinates [][]float64 `json:"coordinates"`
 }
 
 // GreenWaveDTO represents a green wave for API communication.
@@ -67,6 +196,8 @@ type GreenWaveDTO struct {
 	TravelTime float64 `json:"travel_time"`
 	// Bandwidth of the green wave in seconds.
 	BandWidth float64 `json:"band_width"`
+	// Road polyline between the two junctions in travel direction (if it has been provided)
+	Geometry []PointDTO `json:"geometry,omitempty"`
 }
 
 // GreenIntervalDTO represents a green interval for API communication.
@@ -91,4 +222,64 @@ type ThroughGreenWaveDTO struct {
 	Depth int `json:"depth"`
 	// Bandwidth of the green wave, which is the minimum duration of the green intervals
 	Bandwidth float64 `json:"bandwidth"`
+	// Bandwidth of the green wave which remains valid for every speed within the speed range. Equals to bandwidth if speed range has not been provided
+	RobustBandwidth float64 `json:"robust_bandwidth"`
+}
+
+// DirectionGreenWavesDTO represents green waves for a single direction of the corridor for API communication.
+// swagger:model
+type DirectionGreenWavesDTO struct {
+	// List of segments of green waves between junctions (in travel order)
+	GreenWaves [][]GreenWaveDTO `json:"green_waves"`
+	// List of through green waves (so they can be passed through multiple junctions)
+	ThroughGreenWaves []ThroughGreenWaveDTO `json:"through_green_waves"`
+}
+
+// CycleCandidateDTO represents result of offsets optimization for a single candidate cycle length for API communication.
+// swagger:model
+type CycleCandidateDTO struct {
+	// Common cycle length in seconds
+	Cycle float64 `json:"cycle"`
+	// Optimal offsets of junctions for the cycle
+	Offsets []float64 `json:"offsets"`
+	// Scaled signal durations of each junction (phases signals in cycle order)
+	Durations [][]float64 `json:"durations"`
+	// Bandwidth of the widest outbound through green wave which passes the whole corridor
+	OutboundBandwidth float64 `json:"outbound_bandwidth"`
+	// Bandwidth of the widest inbound through green wave which passes the whole corridor (two-way requests only)
+	InboundBandwidth float64 `json:"inbound_bandwidth"`
+	// Bandwidth share of the cycle (averaged over directions)
+	Efficiency float64 `json:"efficiency"`
+}
+
+// ParetoSolutionDTO represents a single non-dominated solution of the multi-objective optimization for API communication.
+// swagger:model
+type ParetoSolutionDTO struct {
+	// Offsets of junctions
+	Offsets []float64 `json:"offsets"`
+	// Bandwidth of the widest outbound through green wave which passes the whole corridor
+	OutboundBandwidth float64 `json:"outbound_bandwidth"`
+	// Bandwidth of the widest inbound through green wave which passes the whole corridor (two-way requests only)
+	InboundBandwidth float64 `json:"inbound_bandwidth"`
+	// Number of through green waves (both directions for two-way requests)
+	ThroughWaves int `json:"through_waves"`
+	// Share of corridor segments passed by at least one green wave (averaged over directions for two-way requests)
+	Coverage float64 `json:"coverage"`
+}
+
+// ProgressDTO represents the state of the optimization after a generation (iteration, temperature step) for API communication.
+// swagger:model
+type ProgressDTO struct {
+	// Index of the generation (iteration, temperature step), starting from 0
+	Generation int `json:"generation"`
+	// Best fitness found so far
+	BestFitness float64 `json:"best_fitness"`
+	// Mean fitness of the current population
+	MeanFitness float64 `json:"mean_fitness"`
+	// Worst fitness of the current population
+	WorstFitness float64 `json:"worst_fitness"`
+	// Circular variance of offsets of the current population averaged over junctions (0 - identical offsets, 1 - uniformly spread)
+	Diversity float64 `json:"diversity"`
+	// Best offsets found so far
+	BestOffsets []float64 `json:"best_offsets"`
 }
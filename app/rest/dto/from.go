@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/LdDl/greenwave"
@@ -8,21 +9,111 @@ import (
 )
 
 // JunctionFromDTO creates a Junction from a DTO
-func JunctionFromDTO(dto JunctionDTO) *greenwave.Junction {
+func JunctionFromDTO(dto JunctionDTO) (*greenwave.Junction, error) {
 	cycle := make([]*greenwave.Phase, len(dto.Cycle))
 	for i, phaseDTO := range dto.Cycle {
 		cycle[i] = PhaseFromDTO(phaseDTO)
 	}
+	point, err := PointFromDTO(dto.Point)
+	if err != nil {
+		return nil, err
+	}
 
 	junction := greenwave.NewJunction(cycle,
 		greenwave.WithID(dto.ID),
 		greenwave.WithLabel(dto.Label),
-		greenwave.WithPoint(greenwave.Point{X: dto.Point.X, Y: dto.Point.Y}))
+		greenwave.WithPoint(point))
+	if dto.EffectiveGreen != nil {
+		greenwave.WithEffectiveGreen(EffectiveGreenFromDTO(*dto.EffectiveGreen))(junction)
+	}
+	if len(dto.SignalGroups) > 0 {
+		signalGroups := make([]*greenwave.SignalGroup, len(dto.SignalGroups))
+		for i, signalGroupDTO := range dto.SignalGroups {
+			signalGroups[i] = SignalGroupFromDTO(signalGroupDTO)
+		}
+		greenwave.WithSignalGroups(signalGroups...)(junction)
+	}
+	if dto.Movement != nil {
+		greenwave.WithMovement(greenwave.NewMovement(dto.Movement.Phases, greenwave.WithMaxGap(dto.Movement.MaxGap)))(junction)
+	}
+	if dto.OffsetConstraint != nil {
+		offsetConstraint, err := OffsetConstraintFromDTO(*dto.OffsetConstraint, dto.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset_constraint: %w", err)
+		}
+		greenwave.WithOffsetConstraint(offsetConstraint)(junction)
+	}
 
 	// Set offset if provided
 	junction.SetOffset(dto.Offset)
 
-	return junction
+	return junction, nil
+}
+
+// OffsetConstraintFromDTO creates an OffsetConstraint from a DTO. Offset of the junction is used for fixed constraint without offset
+// and for window constraint defined by tolerance. Returns error for unknown constraint type or incomplete parameters
+func OffsetConstraintFromDTO(dto OffsetConstraintDTO, junctionOffset float64) (*greenwave.OffsetConstraint, error) {
+	switch strings.ToLower(dto.Type) {
+	case "fixed":
+		if dto.Offset != nil {
+			return greenwave.NewFixedOffset(*dto.Offset), nil
+		}
+		return greenwave.NewFixedOffset(junctionOffset), nil
+	case "window":
+		if (dto.Min == nil) != (dto.Max == nil) {
+			return nil, fmt.Errorf("both min and max must be provided")
+		}
+		if dto.Min != nil {
+			if *dto.Min > *dto.Max {
+				return nil, fmt.Errorf("min must not be greater than max")
+			}
+			return greenwave.NewOffsetWindow(*dto.Min, *dto.Max), nil
+		}
+		if dto.Tolerance <= 0 {
+			return nil, fmt.Errorf("either min and max or positive tolerance must be provided")
+		}
+		return greenwave.NewOffsetWindow(junctionOffset-dto.Tolerance, junctionOffset+dto.Tolerance), nil
+	case "discrete":
+		if len(dto.Offsets) == 0 {
+			return nil, fmt.Errorf("at least one allowed offset must be provided")
+		}
+		return greenwave.NewDiscreteOffsets(dto.Offsets...), nil
+	default:
+		return nil, fmt.Errorf("unsupported type: '%s', expected 'fixed', 'window' or 'discrete'", dto.Type)
+	}
+}
+
+// EffectiveGreenFromDTO creates an EffectiveGreen from a DTO
+func EffectiveGreenFromDTO(dto EffectiveGreenDTO) *greenwave.EffectiveGreen {
+	return &greenwave.EffectiveGreen{
+		StartupLostTime: dto.StartupLostTime,
+		YellowExtension: dto.YellowExtension,
+		UseRedYellow:    dto.UseRedYellow,
+		UseGreenRight:   dto.UseGreenRight,
+	}
+}
+
+// PointFromDTO creates a Point from a DTO. Returns error for unknown coordinate reference system
+func PointFromDTO(dto PointDTO) (greenwave.Point, error) {
+	var crs greenwave.CRS
+	switch strings.ToLower(dto.CRS) {
+	case "", "planar":
+		crs = greenwave.CRS_PLANAR
+	case "wgs84":
+		crs = greenwave.CRS_WGS84
+	default:
+		return greenwave.Point{}, fmt.Errorf("unsupported coordinate reference system: '%s', expected 'planar' or 'wgs84'", dto.CRS)
+	}
+	return greenwave.Point{X: dto.X, Y: dto.Y, CRS: crs}, nil
+}
+
+// SignalGroupFromDTO creates a SignalGroup from a DTO
+func SignalGroupFromDTO(dto SignalGroupDTO) *greenwave.SignalGroup {
+	signals := make([]*greenwave.Signal, len(dto.Signals))
+	for i, signalDTO := range dto.Signals {
+		signals[i] = SignalFromDTO(signalDTO)
+	}
+	return greenwave.NewSignalGroup(dto.Name, signals)
 }
 
 // PhaseFromDTO creates a Phase from a DTO
@@ -69,3 +160,44 @@ func SignalFromDTO(dto SignalDTO) *greenwave.Signal {
 	}
 	return signal
 }
+
+// SegmentFromDTO creates a Segment from a DTO
+func SegmentFromDTO(dto SegmentDTO) (*greenwave.Segment, error) {
+	var geometry []greenwave.Point
+	if len(dto.Geometry) > 0 {
+		geometry = make([]greenwave.Point, len(dto.Geometry))
+		for i, pointDTO := range dto.Geometry {
+			point, err := PointFromDTO(pointDTO)
+			if err != nil {
+				return nil, fmt.Errorf("geometry point %d: %w", i, err)
+			}
+			geometry[i] = point
+		}
+	} else if dto.GeoJSON != nil {
+		var err error
+		geometry, err = LineStringFromDTO(*dto.GeoJSON)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return greenwave.NewSegment(dto.DistanceMeters,
+		greenwave.WithSegmentGeometry(geometry),
+		greenwave.WithSegmentSpeed(dto.SpeedKmh),
+		greenwave.WithSegmentTravelTime(dto.TravelTimeSeconds),
+		greenwave.WithSegmentSpeedLimits(dto.MinSpeedKmh, dto.MaxSpeedKmh)), nil
+}
+
+// LineStringFromDTO creates a list of WGS84 points from GeoJSON LineString DTO
+func LineStringFromDTO(dto LineStringDTO) ([]greenwave.Point, error) {
+	if dto.Type != "LineString" {
+		return nil, fmt.Errorf("unsupported GeoJSON geometry type: '%s', expected 'LineString'", dto.Type)
+	}
+	points := make([]greenwave.Point, len(dto.Coordinates))
+	for i, position := range dto.Coordinates {
+		if len(position) < 2 {
+			return nil, fmt.Errorf("GeoJSON position %d must contain longitude and latitude", i)
+		}
+		points[i] = greenwave.NewPointWGS84(position[0], position[1])
+	}
+	return points, nil
+}
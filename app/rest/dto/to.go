@@ -9,17 +9,107 @@ func JunctionToDTO(junction *greenwave.Junction) JunctionDTO {
 		cycleDTO[i] = PhaseToDTO(phase)
 	}
 
+	var signalGroupsDTO []SignalGroupDTO
+	if signalGroups := junction.GetSignalGroups(); len(signalGroups) > 0 {
+		signalGroupsDTO = make([]SignalGroupDTO, len(signalGroups))
+		for i, signalGroup := range signalGroups {
+			signalGroupsDTO[i] = SignalGroupToDTO(signalGroup)
+		}
+	}
+
 	point := junction.GetPoint()
 	return JunctionDTO{
-		ID:            junction.ID,
-		Label:         junction.Label,
-		Cycle:         cycleDTO,
-		TotalDuration: junction.GetTotalDuration(),
-		Offset:        junction.GetOffset(),
-		Point:         PointDTO{X: point.X, Y: point.Y},
+		ID:               junction.ID,
+		Label:            junction.Label,
+		Cycle:            cycleDTO,
+		TotalDuration:    junction.GetTotalDuration(),
+		Offset:           junction.GetOffset(),
+		Point:            PointToDTO(point),
+		EffectiveGreen:   EffectiveGreenToDTO(junction.GetEffectiveGreen()),
+		Movement:         MovementToDTO(junction.GetMovement()),
+		SignalGroups:     signalGroupsDTO,
+		OffsetConstraint: OffsetConstraintToDTO(junction.GetOffsetConstraint()),
+	}
+}
+
+// OffsetConstraintToDTO converts an OffsetConstraint to a DTO
+func OffsetConstraintToDTO(constraint *greenwave.OffsetConstraint) *OffsetConstraintDTO {
+	if constraint == nil {
+		return nil
+	}
+	constraintDTO := &OffsetConstraintDTO{
+		Type: constraint.Type().String(),
+	}
+	minOffset, maxOffset := constraint.Bounds()
+	switch constraint.Type() {
+	case greenwave.OFFSET_CONSTRAINT_FIXED:
+		constraintDTO.Offset = &minOffset
+	case greenwave.OFFSET_CONSTRAINT_WINDOW:
+		constraintDTO.Min, constraintDTO.Max = &minOffset, &maxOffset
+	case greenwave.OFFSET_CONSTRAINT_DISCRETE:
+		constraintDTO.Offsets = constraint.Offsets()
+	}
+	return constraintDTO
+}
+
+// SignalGroupToDTO converts a SignalGroup to a DTO
+func SignalGroupToDTO(signalGroup *greenwave.SignalGroup) SignalGroupDTO {
+	signalsDTO := make([]SignalDTO, len(signalGroup.Signals))
+	for i, signal := range signalGroup.Signals {
+		signalsDTO[i] = SignalToDTO(signal)
+	}
+	return SignalGroupDTO{
+		Name:         signalGroup.Name,
+		Signals:      signalsDTO,
+		TotalSeconds: signalGroup.GetTotalSeconds(),
+	}
+}
+
+// MovementToDTO converts a Movement to a DTO
+func MovementToDTO(movement *greenwave.Movement) *MovementDTO {
+	if movement == nil {
+		return nil
+	}
+	return &MovementDTO{
+		Phases: movement.Phases,
+		MaxGap: movement.MaxGap,
+	}
+}
+
+// EffectiveGreenToDTO converts an EffectiveGreen to a DTO
+func EffectiveGreenToDTO(effectiveGreen *greenwave.EffectiveGreen) *EffectiveGreenDTO {
+	if effectiveGreen == nil {
+		return nil
+	}
+	return &EffectiveGreenDTO{
+		StartupLostTime: effectiveGreen.StartupLostTime,
+		YellowExtension: effectiveGreen.YellowExtension,
+		UseRedYellow:    effectiveGreen.UseRedYellow,
+		UseGreenRight:   effectiveGreen.UseGreenRight,
+	}
+}
+
+// PointToDTO converts a Point to a DTO
+func PointToDTO(point greenwave.Point) PointDTO {
+	return PointDTO{
+		X:   point.X,
+		Y:   point.Y,
+		CRS: point.CRS.String(),
 	}
 }
 
+// PointsToDTO converts a list of Point to a list of DTO. Returns nil for empty list
+func PointsToDTO(points []greenwave.Point) []PointDTO {
+	if len(points) == 0 {
+		return nil
+	}
+	pointsDTO := make([]PointDTO, len(points))
+	for i, point := range points {
+		pointsDTO[i] = PointToDTO(point)
+	}
+	return pointsDTO
+}
+
 // PhaseToDTO converts a Phase to a DTO
 func PhaseToDTO(phase *greenwave.Phase) PhaseDTO {
 	signalsDTO := make([]SignalDTO, len(phase.Signals))
@@ -44,6 +134,18 @@ func SignalToDTO(signal *greenwave.Signal) SignalDTO {
 	}
 }
 
+// SegmentToDTO converts a Segment to a DTO
+func SegmentToDTO(segment *greenwave.Segment) SegmentDTO {
+	return SegmentDTO{
+		DistanceMeters:    segment.DistanceMeters,
+		Geometry:          PointsToDTO(segment.Geometry),
+		SpeedKmh:          segment.SpeedKmh,
+		TravelTimeSeconds: segment.TravelTimeSeconds,
+		MinSpeedKmh:       segment.MinSpeedKmh,
+		MaxSpeedKmh:       segment.MaxSpeedKmh,
+	}
+}
+
 // GreenIntervalToDTO converts a GreenInterval to a DTO
 func GreenIntervalToDTO(interval *greenwave.GreenInterval) *GreenIntervalDTO {
 	if interval == nil {
@@ -65,6 +167,7 @@ func GreenWaveToDTO(wave *greenwave.GreenWave) GreenWaveDTO {
 		Distance:       wave.Distance(),
 		TravelTime:     wave.TravelTime(),
 		BandWidth:      wave.Bandwidth(),
+		Geometry:       PointsToDTO(wave.Geometry()),
 	}
 }
 
@@ -76,8 +179,44 @@ func ThroughGreenWaveToDTO(wave *greenwave.ThroughGreenWave) ThroughGreenWaveDTO
 		intervalDTOs[i] = *GreenIntervalToDTO(interval)
 	}
 	return ThroughGreenWaveDTO{
-		Intervals: intervalDTOs,
-		Depth:     wave.Depth(),
-		Bandwidth: wave.Bandwidth(),
+		Intervals:       intervalDTOs,
+		Depth:           wave.Depth(),
+		Bandwidth:       wave.Bandwidth(),
+		RobustBandwidth: wave.RobustBandwidth(),
+	}
+}
+
+// CycleCandidateToDTO converts a CycleCandidate to a DTO
+func CycleCandidateToDTO(candidate *greenwave.CycleCandidate) CycleCandidateDTO {
+	return CycleCandidateDTO{
+		Cycle:             candidate.Cycle,
+		Offsets:           candidate.Offsets,
+		Durations:         candidate.Durations,
+		OutboundBandwidth: candidate.OutboundBandwidth,
+		InboundBandwidth:  candidate.InboundBandwidth,
+		Efficiency:        candidate.Efficiency,
+	}
+}
+
+// ParetoSolutionToDTO converts a ParetoSolution to a DTO
+func ParetoSolutionToDTO(solution *greenwave.ParetoSolution) ParetoSolutionDTO {
+	return ParetoSolutionDTO{
+		Offsets:           solution.Offsets,
+		OutboundBandwidth: solution.Objectives.OutboundBandwidth,
+		InboundBandwidth:  solution.Objectives.InboundBandwidth,
+		ThroughWaves:      solution.Objectives.ThroughWaves,
+		Coverage:          solution.Objectives.Coverage,
+	}
+}
+
+// ProgressToDTO converts a Progress to a DTO
+func ProgressToDTO(progress greenwave.Progress) ProgressDTO {
+	return ProgressDTO{
+		Generation:   progress.Generation,
+		BestFitness:  progress.BestFitness,
+		MeanFitness:  progress.MeanFitness,
+		WorstFitness: progress.WorstFitness,
+		Diversity:    progress.Diversity,
+		BestOffsets:  progress.BestOffsets,
 	}
 }
@@ -2,7 +2,9 @@ package rest
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"math"
 
 	"github.com/LdDl/greenwave"
 	"github.com/LdDl/greenwave/app/rest/dto"
@@ -17,15 +19,43 @@ type GreenWavesRequest struct {
 	Junctions []dto.JunctionDTO `json:"junctions"`
 	// Desired speed in km/h for calculating green waves
 	DesiredSpeedKmh float64 `json:"desired_speed_kmh"`
+	// Optional corridor settings (segments, speed range, effective green policy)
+	CorridorOptions
+	// Enables two-way extraction: inbound direction (from the last junction to the first one) is calculated too
+	TwoWay bool `json:"two_way"`
+	// Desired speed in km/h for the inbound direction. If not provided then desired_speed_kmh is used
+	InboundSpeedKmh float64 `json:"inbound_speed_kmh"`
+}
+
+// CorridorOptions contains optional corridor settings shared by extraction and optimization requests.
+// swagger:model
+type CorridorOptions struct {
+	// Optional corridor links between consecutive junctions (N-1 items for N junctions).
+	// If not provided then straight line distance between junctions points and desired speed are used
+	Segments []dto.SegmentDTO `json:"segments"`
+	// Optional speed range for evaluating speed-robust bandwidth of through green waves
+	SpeedRange *dto.SpeedRangeDTO `json:"speed_range"`
+	// Optional effective green policy for junctions which have no own policy
+	EffectiveGreen *dto.EffectiveGreenDTO `json:"effective_green"`
+	// Optional selection of coordinated signal groups per junction for each direction
+	CoordinatedGroups *dto.CoordinatedGroupsDTO `json:"coordinated_groups"`
+	// Optional upper bound of the corridor hyperperiod in seconds. Default is 3600.
+	// If the least common multiple of cycle lengths exceeds it then each junction is analysed over its own cycle only
+	MaxHyperperiod float64 `json:"max_hyperperiod"`
 }
 
 // GreenWavesResponse represents the response structure for green waves requests.
 // swagger:model
 type GreenWavesResponse struct {
-	// List of segments of green waves between junctions
-	GreenWaves [][]dto.GreenWaveDTO `json:"green_waves"`
-	// List of through green waves (so they can be passed through multiple junctions)
-	ThroughGreenWaves []dto.ThroughGreenWaveDTO `json:"through_green_waves"`
+	// Hyperperiod (least common multiple of cycle lengths) in seconds which corridor has been analysed over. Green waves are reported within a single hyperperiod.
+	// 0 means that the hyperperiod exceeds max_hyperperiod and each junction has been analysed over its own cycle only
+	Hyperperiod float64 `json:"hyperperiod"`
+	// True if the hyperperiod exceeds max_hyperperiod, so it has not been used
+	HyperperiodExceeded bool `json:"hyperperiod_exceeded,omitempty"`
+	// Green waves from the first junction towards the last one
+	Outbound dto.DirectionGreenWavesDTO `json:"outbound"`
+	// Green waves from the last junction towards the first one. Presented only for two-way requests
+	Inbound *dto.DirectionGreenWavesDTO `json:"inbound,omitempty"`
 }
 
 // ExtractGreenWaves returns green waves for traffic lights configuration.
@@ -58,24 +88,159 @@ func ExtractGreenWaves() func(ctx echo.Context) error {
 			})
 		}
 
-		junctions := make([]*greenwave.Junction, len(requestData.Junctions))
-		for i, junctionDTO := range requestData.Junctions {
-			junctions[i] = dto.JunctionFromDTO(junctionDTO)
+		junctions, err := junctionsFromDTO(requestData.Junctions)
+		if err != nil {
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+		extractOptions, err := prepareExtractOptions(junctions, requestData.CorridorOptions)
+		if err != nil {
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
 		}
 
 		// Extract green waves
-		greenWaves := greenwave.FindGreenWaves(junctions, requestData.DesiredSpeedKmh)
-		throughGreenWaves := greenwave.MergeGreenWaves(greenWaves)
-
+		greenWaves := greenwave.FindGreenWaves(junctions, requestData.DesiredSpeedKmh, extractOptions...)
+		hyperperiod, ok := greenwave.CorridorHyperperiod(junctions, extractOptions...)
 		response := GreenWavesResponse{
-			GreenWaves:        convertGreenWavesToDTO(greenWaves),
-			ThroughGreenWaves: convertThroughGreenWavesToDTO(throughGreenWaves),
+			Hyperperiod:         hyperperiod,
+			HyperperiodExceeded: !ok,
+			Outbound:            convertDirectionToDTO(greenWaves),
+		}
+		if requestData.TwoWay {
+			inboundGreenWaves := greenwave.FindGreenWavesInbound(junctions, inboundSpeed(requestData.DesiredSpeedKmh, requestData.InboundSpeedKmh), extractOptions...)
+			inbound := convertDirectionToDTO(inboundGreenWaves)
+			response.Inbound = &inbound
 		}
 
 		return ctx.JSON(200, response)
 	}
 }
 
+// junctionsFromDTO converts junctions DTOs to domain objects
+func junctionsFromDTO(junctionsDTO []dto.JunctionDTO) ([]*greenwave.Junction, error) {
+	junctions := make([]*greenwave.Junction, len(junctionsDTO))
+	for i, junctionDTO := range junctionsDTO {
+		junction, err := dto.JunctionFromDTO(junctionDTO)
+		if err != nil {
+			return nil, fmt.Errorf("junction %d: %w", i, err)
+		}
+		junctions[i] = junction
+	}
+	return junctions, nil
+}
+
+// prepareExtractOptions converts corridor related request fields to green waves extraction options
+func prepareExtractOptions(junctions []*greenwave.Junction, corridorOptions CorridorOptions) ([]func(*greenwave.ExtractSettings), error) {
+	segmentsDTO := corridorOptions.Segments
+	speedRangeDTO := corridorOptions.SpeedRange
+	extractOptions := []func(*greenwave.ExtractSettings){}
+	junctionsNum := len(junctions)
+	for i := 1; i < junctionsNum; i++ {
+		if junctions[i].GetPoint().CRS != junctions[0].GetPoint().CRS {
+			return nil, fmt.Errorf("all junctions must share the same coordinate reference system: junction %d has '%s', expected '%s'", i, junctions[i].GetPoint().CRS, junctions[0].GetPoint().CRS)
+		}
+	}
+	for i, junction := range junctions {
+		for _, signalGroup := range junction.GetSignalGroups() {
+			if math.Abs(signalGroup.GetTotalSeconds()-junction.GetTotalDuration()) > 1e-6 {
+				return nil, fmt.Errorf("junction %d: signal group '%s' lasts %g seconds, expected %g (cycle duration)", i, signalGroup.Name, signalGroup.GetTotalSeconds(), junction.GetTotalDuration())
+			}
+		}
+	}
+	if len(segmentsDTO) > 0 {
+		if len(segmentsDTO) != junctionsNum-1 {
+			return nil, fmt.Errorf("number of segments must be equal to number of junctions minus one: expected %d, got %d", junctionsNum-1, len(segmentsDTO))
+		}
+		segments := make([]*greenwave.Segment, len(segmentsDTO))
+		for i, segmentDTO := range segmentsDTO {
+			if segmentDTO.DistanceMeters < 0 || segmentDTO.SpeedKmh < 0 || segmentDTO.TravelTimeSeconds < 0 {
+				return nil, fmt.Errorf("segment %d: distance, speed and travel time must be non-negative", i)
+			}
+			if segmentDTO.MinSpeedKmh > 0 && segmentDTO.MaxSpeedKmh > 0 && segmentDTO.MinSpeedKmh > segmentDTO.MaxSpeedKmh {
+				return nil, fmt.Errorf("segment %d: min speed must not be greater than max speed", i)
+			}
+			segment, err := dto.SegmentFromDTO(segmentDTO)
+			if err != nil {
+				return nil, fmt.Errorf("segment %d: %w", i, err)
+			}
+			for j, point := range segment.Geometry {
+				if point.CRS != junctions[0].GetPoint().CRS {
+					return nil, fmt.Errorf("segment %d: geometry point %d must share the coordinate reference system of junctions", i, j)
+				}
+			}
+			segments[i] = segment
+		}
+		extractOptions = append(extractOptions, greenwave.WithSegments(segments))
+	}
+	if speedRangeDTO != nil {
+		minSpeedKmh, maxSpeedKmh := speedRangeDTO.MinSpeedKmh, speedRangeDTO.MaxSpeedKmh
+		if minSpeedKmh <= 0 && maxSpeedKmh <= 0 {
+			coverage := speedRangeDTO.Coverage
+			if coverage == 0 {
+				coverage = 0.85
+			}
+			if speedRangeDTO.MeanSpeedKmh <= 0 || speedRangeDTO.StdDevKmh < 0 || coverage <= 0 || coverage >= 1 {
+				return nil, fmt.Errorf("speed range: either min/max speed or positive mean speed, non-negative standard deviation and coverage within (0; 1) must be provided")
+			}
+			minSpeedKmh, maxSpeedKmh = greenwave.SpeedRangeFromDistribution(speedRangeDTO.MeanSpeedKmh, speedRangeDTO.StdDevKmh, coverage)
+		}
+		if minSpeedKmh <= 0 || maxSpeedKmh < minSpeedKmh {
+			return nil, fmt.Errorf("speed range: min speed must be positive and not greater than max speed")
+		}
+		extractOptions = append(extractOptions, greenwave.WithSpeedRange(minSpeedKmh, maxSpeedKmh))
+	}
+	if corridorOptions.CoordinatedGroups != nil {
+		outbound, inbound := corridorOptions.CoordinatedGroups.Outbound, corridorOptions.CoordinatedGroups.Inbound
+		for _, groups := range [][]string{outbound, inbound} {
+			if len(groups) == 0 {
+				continue
+			}
+			if len(groups) != junctionsNum {
+				return nil, fmt.Errorf("number of coordinated signal groups must be equal to number of junctions: expected %d, got %d", junctionsNum, len(groups))
+			}
+			for i, groupName := range groups {
+				if groupName != "" && junctions[i].GetSignalGroup(groupName) == nil {
+					return nil, fmt.Errorf("junction %d has no signal group '%s'", i, groupName)
+				}
+			}
+		}
+		extractOptions = append(extractOptions, greenwave.WithCoordinatedSignalGroups(outbound, inbound))
+	}
+	if corridorOptions.MaxHyperperiod < 0 {
+		return nil, fmt.Errorf("max hyperperiod must be non-negative")
+	}
+	if corridorOptions.MaxHyperperiod > 0 {
+		extractOptions = append(extractOptions, greenwave.WithMaxHyperperiod(corridorOptions.MaxHyperperiod))
+	}
+	if corridorOptions.EffectiveGreen != nil {
+		if corridorOptions.EffectiveGreen.StartupLostTime < 0 || corridorOptions.EffectiveGreen.YellowExtension < 0 {
+			return nil, fmt.Errorf("effective green: start-up lost time and yellow extension must be non-negative")
+		}
+		extractOptions = append(extractOptions, greenwave.WithCorridorEffectiveGreen(dto.EffectiveGreenFromDTO(*corridorOptions.EffectiveGreen)))
+	}
+	return extractOptions, nil
+}
+
+// inboundSpeed returns speed for the inbound direction falling back to the outbound one if it is not provided
+func inboundSpeed(outboundSpeedKmh, inboundSpeedKmh float64) float64 {
+	if inboundSpeedKmh <= 0 {
+		return outboundSpeedKmh
+	}
+	return inboundSpeedKmh
+}
+
+// convertDirectionToDTO merges green waves into through green waves and converts both to the single direction DTO
+func convertDirectionToDTO(greenWaves [][]*greenwave.GreenWave) dto.DirectionGreenWavesDTO {
+	throughGreenWaves := greenwave.MergeGreenWaves(greenWaves)
+	return dto.DirectionGreenWavesDTO{
+		GreenWaves:        convertGreenWavesToDTO(greenWaves),
+		ThroughGreenWaves: convertThroughGreenWavesToDTO(throughGreenWaves),
+	}
+}
+
 // convertGreenWavesToDTO converts a slice of GreenWave to GreenWaveDTO
 func convertGreenWavesToDTO(greenWaves [][]*greenwave.GreenWave) [][]dto.GreenWaveDTO {
 	result := make([][]dto.GreenWaveDTO, len(greenWaves))
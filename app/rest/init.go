@@ -49,10 +49,16 @@ func Setup(appCfg *configuration.Configuration) *echo.Echo {
 func MainAPI(app *echo.Echo, appCfg *configuration.Configuration) {
 	mainGroup := app.Group(fmt.Sprintf("/%s", appCfg.ServerCfg.MainPath))
 	routerGroup := mainGroup.Group("/greenwave")
+	jobManager := NewJobManager(appCfg.JobsCfg)
 	{
 		routerGroup.Static("/docs", appCfg.DocsFolder)
 		routerGroup.GET("/health", GetHealth())
 		routerGroup.POST("/extract", ExtractGreenWaves())
 		routerGroup.POST("/optimize", RequestOptimize())
+		routerGroup.POST("/optimize/stream", RequestOptimizeStream())
+		routerGroup.POST("/optimize/cycle", RequestCycleSearch())
+		routerGroup.POST("/optimize/jobs", CreateOptimizeJob(jobManager))
+		routerGroup.GET("/optimize/jobs/:id", GetOptimizeJob(jobManager))
+		routerGroup.DELETE("/optimize/jobs/:id", CancelOptimizeJob(jobManager))
 	}
 }
@@ -1,10 +1,14 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand/v2"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/LdDl/greenwave"
 	"github.com/LdDl/greenwave/app/rest/dto"
@@ -19,10 +23,42 @@ type OptimizeRequest struct {
 	Junctions []dto.JunctionDTO `json:"junctions"`
 	// Desired speed in km/h for calculating green waves
 	DesiredSpeedKmh float64 `json:"desired_speed_kmh"`
+	// Optional corridor settings (segments, speed range, effective green policy)
+	CorridorOptions
+	// Enables two-way optimization: inbound direction (from the last junction to the first one) is considered too
+	TwoWay bool `json:"two_way"`
+	// Desired speed in km/h for the inbound direction. If not provided then desired_speed_kmh is used
+	InboundSpeedKmh float64 `json:"inbound_speed_kmh"`
+	// Weight of the outbound direction in the combined objective for two-way optimization. Default is 1.0
+	OutboundWeight *float64 `json:"outbound_weight"`
+	// Weight of the inbound direction in the combined objective for two-way optimization. Default is 1.0
+	InboundWeight *float64 `json:"inbound_weight"`
+	// Resolution in seconds of the resulting offsets, e.g. 0.1 for controllers working in tenths of a second.
+	// The final plan is rounded and re-evaluated. Zero (default) means no rounding
+	OutputResolution float64 `json:"output_resolution"`
+	// Optional green split optimization: signal durations within min/max bounds are optimized alongside offsets.
+	// Supported by genetic optimizer only
+	GreenSplit *dto.GreenSplitDTO `json:"green_split"`
 	// Specifies which optimizer to use
 	OptimizerType string `json:"optimizer_type"`
-	// Contains parameters for the optimizer
+	// Return the whole Pareto front of offsets alongside best offsets. Supported by multi-objective optimizer ("nsga2") only
+	ParetoFront bool `json:"pareto_front"`
+	// Contains parameters for the optimizer. Parameters which are common for every optimizer:
+	// "fitness" - maximized objective: "depth_weighted" (default), "max_band", "coverage", "stops" or "weighted";
+	// "fitness_weights" - weights of built-in objectives for "weighted" fitness, e.g. {"max_band": 1, "stops": 10}
 	OptimizerParams map[string]interface{} `json:"optimizer_params"`
+	// Wall-clock time budget of stochastic optimizers in seconds. Best offsets found so far are returned when it is exhausted. Zero (default) means no limit
+	TimeBudget float64 `json:"time_budget"`
+	// Stochastic optimizers stop when the best fitness has not been improved for the given number of generations (iterations). Zero (default) means no limit
+	MaxStagnantGenerations int `json:"max_stagnant_generations"`
+	// Stochastic optimizers stop as soon as the best fitness reaches the target
+	TargetFitness *float64 `json:"target_fitness"`
+	// Return per-generation progress (best/mean/worst fitness, diversity and best offsets) in optimizer_extra.
+	// Supported by genetic, annealing, pso and nsga2 optimizers
+	Progress bool `json:"progress"`
+	// Seed of the random source of stochastic optimizers. The same seed and input give the same result.
+	// If not provided then random seed is used (it is returned in optimizer_extra)
+	Seed *uint64 `json:"seed"`
 }
 
 // OptimizeResponse represents the response structure for optimization requests.
@@ -32,19 +68,50 @@ type OptimizeResponse struct {
 	BestOffsets []float64 `json:"best_offsets"`
 	// Additional information about the optimization process
 	OptimizerExtra OptimizerExtra `json:"optimizer_extra"`
-	// List of segments of green waves between junctions considering the optimal offsets
-	GreenWaves [][]dto.GreenWaveDTO `json:"green_waves"`
-	// List of through green waves (so they can be passed through multiple junctions) considering the optimal offsets
-	ThroughGreenWaves []dto.ThroughGreenWaveDTO `json:"through_green_waves"`
+	// Hyperperiod (least common multiple of cycle lengths) in seconds which corridor has been analysed over. Green waves are reported within a single hyperperiod.
+	// 0 means that the hyperperiod exceeds max_hyperperiod and each junction has been analysed over its own cycle only
+	Hyperperiod float64 `json:"hyperperiod"`
+	// True if the hyperperiod exceeds max_hyperperiod, so it has not been used
+	HyperperiodExceeded bool `json:"hyperperiod_exceeded,omitempty"`
+	// Green waves from the first junction towards the last one considering the optimal offsets
+	Outbound dto.DirectionGreenWavesDTO `json:"outbound"`
+	// Green waves from the last junction towards the first one considering the optimal offsets. Presented only for two-way requests
+	Inbound *dto.DirectionGreenWavesDTO `json:"inbound,omitempty"`
+	// Junctions with adjusted signal durations (cycle) and optimal offsets. Presented only for green split optimization
+	Junctions []dto.JunctionDTO `json:"junctions,omitempty"`
+	// Non-dominated offsets with their objectives sorted by outbound bandwidth (descending). Presented only if pareto_front is requested
+	ParetoFront []dto.ParetoSolutionDTO `json:"pareto_front,omitempty"`
 }
 
 // OptimizerExtra contains additional information about the optimization process.
 // swagger:model
 type OptimizerExtra struct {
 	// Contains the fitness evolution over generations
-	// Will be represented in case of genetic algorithm
-	// Each value is the best fitness of the population in that generation
+	// Will be represented in case of genetic algorithm, simulated annealing or particle swarm optimization
+	// Each value is the best fitness of the population in that generation (after that temperature step for simulated annealing, of the swarm in that iteration for particle swarm optimization)
 	FitnessHistory []float64 `json:"fitness_history"`
+	// Fitness of the best offsets (after rounding to the output resolution)
+	BestFitness float64 `json:"best_fitness"`
+	// Optimal outbound band in seconds
+	// Will be represented in case of MAXBAND optimizer
+	OutboundBandwidth float64 `json:"outbound_bandwidth,omitempty"`
+	// Optimal inbound band in seconds (two-way requests only)
+	// Will be represented in case of MAXBAND optimizer
+	InboundBandwidth float64 `json:"inbound_bandwidth,omitempty"`
+	// True if the offsets are proven optimal. False if the solver has reached its node limit and returned the best plan found so far
+	// Will be represented in case of MAXBAND optimizer
+	Optimal *bool `json:"optimal,omitempty"`
+	// Seed of the random source actually used. Pass it in the request to reproduce the result
+	Seed uint64 `json:"seed"`
+	// Reason of stopping: completed, deadline, stagnation or target_fitness
+	// Will be represented in case of stochastic optimizers
+	StopReason string `json:"stop_reason,omitempty"`
+	// Number of completed generations (iterations, temperature steps)
+	// Will be represented in case of stochastic optimizers
+	Iterations int `json:"iterations,omitempty"`
+	// Progress of the optimization after each generation (iteration, temperature step)
+	// Will be represented if progress is requested
+	Progress []dto.ProgressDTO `json:"progress,omitempty"`
 }
 
 // RequestOptimize return best offsets with green waves for traffic lights configuration.
@@ -59,129 +126,369 @@ type OptimizerExtra struct {
 // @Router /api/greenwave/optimize [POST]
 func RequestOptimize() func(ctx echo.Context) error {
 	return func(ctx echo.Context) error {
-		bodyBytes, err := io.ReadAll(ctx.Request().Body)
+		requestData, err := bindOptimizeRequest(ctx)
 		if err != nil {
-			errReason := "Can't read body"
-			log.Error().Err(err).Str("scope", "api").Str("method", ctx.Request().Method).Str("route", ctx.Request().URL.Path).RawJSON("req_body", bodyBytes).Msg(errReason)
 			return ctx.JSON(400, echo.Map{
 				"Error": err.Error(),
 			})
 		}
-		requestData := OptimizeRequest{}
-		err = json.Unmarshal(bodyBytes, &requestData)
+		task, err := prepareOptimization(requestData)
 		if err != nil {
-			errReason := "Can't unmarshal request data"
-			log.Error().Err(err).Str("scope", "api").Str("method", ctx.Request().Method).Str("route", ctx.Request().URL.Path).RawJSON("req_body", bodyBytes).Msg(errReason)
 			return ctx.JSON(400, echo.Map{
 				"Error": err.Error(),
 			})
 		}
-
-		// Validate input
-		if len(requestData.Junctions) < 2 {
-			return ctx.JSON(400, echo.Map{
-				"Error": "At least 2 junctions are required",
-			})
+		// Context-aware optimizers stop as soon as the client disconnects
+		response, ok := task.run(ctx.Request().Context())
+		if !ok {
+			log.Warn().Str("scope", "api").Str("method", ctx.Request().Method).Str("route", ctx.Request().URL.Path).Msg("Optimization has been cancelled")
+			return nil // Client has gone, nobody to respond to
 		}
-		if requestData.DesiredSpeedKmh <= 0 {
-			return ctx.JSON(400, echo.Map{
-				"Error": "Desired speed must be greater than 0",
-			})
+		return ctx.JSON(200, response)
+	}
+}
+
+// bindOptimizeRequest reads optimization request from the body
+func bindOptimizeRequest(ctx echo.Context) (OptimizeRequest, error) {
+	requestData := OptimizeRequest{}
+	bodyBytes, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		errReason := "Can't read body"
+		log.Error().Err(err).Str("scope", "api").Str("method", ctx.Request().Method).Str("route", ctx.Request().URL.Path).RawJSON("req_body", bodyBytes).Msg(errReason)
+		return requestData, err
+	}
+	err = json.Unmarshal(bodyBytes, &requestData)
+	if err != nil {
+		errReason := "Can't unmarshal request data"
+		log.Error().Err(err).Str("scope", "api").Str("method", ctx.Request().Method).Str("route", ctx.Request().URL.Path).RawJSON("req_body", bodyBytes).Msg(errReason)
+		return requestData, err
+	}
+	return requestData, nil
+}
+
+// optimization is the validated optimization request which is ready to run
+type optimization struct {
+	requestData    OptimizeRequest
+	junctions      []*greenwave.Junction
+	extractOptions []func(*greenwave.ExtractSettings)
+	optimizer      greenwave.Optimizer
+	seed           uint64
+	// progress is collected if it is requested
+	progress []dto.ProgressDTO
+}
+
+// prepareOptimization validates the request and creates the optimizer. Observers are subscribed to the optimization progress
+func prepareOptimization(requestData OptimizeRequest, observers ...greenwave.Observer) (*optimization, error) {
+	// Validate input
+	if len(requestData.Junctions) < 2 {
+		return nil, fmt.Errorf("At least 2 junctions are required")
+	}
+	if requestData.DesiredSpeedKmh <= 0 {
+		return nil, fmt.Errorf("Desired speed must be greater than 0")
+	}
+
+	// Convert DTOs to domain objects
+	junctions, err := junctionsFromDTO(requestData.Junctions)
+	if err != nil {
+		return nil, err
+	}
+	task := &optimization{
+		requestData: requestData,
+		junctions:   junctions,
+	}
+
+	extractOptions, settingsOptions, seed, err := prepareOptimizerOptions(task.junctions, requestData)
+	if err != nil {
+		return nil, err
+	}
+	task.extractOptions, task.seed = extractOptions, seed
+
+	// Subscribe to the optimization progress
+	if requestData.Progress {
+		settingsOptions = append(settingsOptions, greenwave.WithObserver(greenwave.ObserverFunc(func(generationProgress greenwave.Progress) bool {
+			log.Debug().Str("scope", "optimizer").Int("generation", generationProgress.Generation).Float64("best_fitness", generationProgress.BestFitness).Float64("diversity", generationProgress.Diversity).Msg("Optimization progress")
+			task.progress = append(task.progress, dto.ProgressToDTO(generationProgress))
+			return true
+		})))
+	}
+	for _, observer := range observers {
+		settingsOptions = append(settingsOptions, greenwave.WithObserver(observer))
+	}
+
+	// Create optimizer based on type
+	task.optimizer, err = createOptimizer(requestData.OptimizerType, task.junctions, requestData.DesiredSpeedKmh, requestData.OptimizerParams, settingsOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := task.optimizer.(*greenwave.OptimizerNSGA2); requestData.ParetoFront && !ok {
+		return nil, fmt.Errorf("Pareto front is supported by nsga2 optimizer only")
+	}
+	return task, nil
+}
+
+// run runs the optimization and prepares the response. Context-aware optimizers stop as soon as the context is cancelled:
+// false is returned in that case
+func (task *optimization) run(ctx context.Context) (*OptimizeResponse, bool) {
+	requestData := task.requestData
+	junctions := task.junctions
+	optimizer := task.optimizer
+	optimizerExtra := OptimizerExtra{
+		Seed: task.seed,
+	}
+
+	// Run optimization
+	var bestOffsets []float64
+	if contextOptimizer, ok := optimizer.(greenwave.ContextOptimizer); ok {
+		result := contextOptimizer.OptimizeContext(ctx)
+		if result.StopReason == greenwave.STOP_CANCELLED {
+			return nil, false
 		}
+		bestOffsets = result.Offsets
+		optimizerExtra.StopReason = result.StopReason.String()
+		optimizerExtra.Iterations = result.Iterations
+	} else {
+		bestOffsets = optimizer.Optimize()
+	}
+	optimizerExtra.Progress = task.progress
 
-		// Convert DTOs to domain objects
-		junctions := make([]*greenwave.Junction, len(requestData.Junctions))
-		for i, junctionDTO := range requestData.Junctions {
-			junctions[i] = dto.JunctionFromDTO(junctionDTO)
+	var bestDurations [][]float64
+	var paretoFront []*greenwave.ParetoSolution
+	switch opt := optimizer.(type) {
+	case *greenwave.OptimizerGenetic:
+		optimizerExtra.FitnessHistory = opt.BestFitnessHistory()
+		optimizerExtra.BestFitness = opt.BestFitness()
+		bestDurations = opt.BestDurations()
+	case *greenwave.OptimizerAnnealing:
+		optimizerExtra.FitnessHistory = opt.BestFitnessHistory()
+		optimizerExtra.BestFitness = opt.BestFitness()
+	case *greenwave.OptimizerPSO:
+		optimizerExtra.FitnessHistory = opt.BestFitnessHistory()
+		optimizerExtra.BestFitness = opt.BestFitness()
+	case *greenwave.OptimizerNSGA2:
+		optimizerExtra.BestFitness = opt.BestFitness()
+		if requestData.ParetoFront {
+			paretoFront = opt.ParetoFront()
 		}
+	case *greenwave.OptimizerDP:
+		optimizerExtra.BestFitness = opt.BestFitness()
+	case *greenwave.OptimizerMaxband:
+		optimizerExtra.BestFitness = opt.BestFitness()
+		optimizerExtra.OutboundBandwidth, optimizerExtra.InboundBandwidth = opt.Bandwidths()
+		optimal := opt.Optimal()
+		optimizerExtra.Optimal = &optimal
+	}
 
-		// Create optimizer based on type
-		optimizer, err := createOptimizer(requestData.OptimizerType, junctions, requestData.DesiredSpeedKmh, requestData.OptimizerParams)
-		if err != nil {
-			return ctx.JSON(400, echo.Map{
-				"Error": err.Error(),
-			})
+	// Apply best offsets (and signal durations) to junctions
+	for i, junction := range junctions {
+		junction.SetOffset(bestOffsets[i])
+		if bestDurations != nil {
+			junction.SetSignalDurations(bestDurations[i])
 		}
+	}
+	// Calculate green waves with optimized offsets
+	greenWaves := greenwave.FindGreenWaves(junctions, requestData.DesiredSpeedKmh, task.extractOptions...)
 
-		// Run optimization
-		bestOffsets := optimizer.Optimize()
-		// Apply best offsets to junctions
+	hyperperiod, ok := greenwave.CorridorHyperperiod(junctions, task.extractOptions...)
+	response := &OptimizeResponse{
+		BestOffsets:         bestOffsets,
+		OptimizerExtra:      optimizerExtra,
+		Hyperperiod:         hyperperiod,
+		HyperperiodExceeded: !ok,
+		Outbound:            convertDirectionToDTO(greenWaves),
+	}
+	if requestData.TwoWay {
+		inboundGreenWaves := greenwave.FindGreenWavesInbound(junctions, inboundSpeed(requestData.DesiredSpeedKmh, requestData.InboundSpeedKmh), task.extractOptions...)
+		inbound := convertDirectionToDTO(inboundGreenWaves)
+		response.Inbound = &inbound
+	}
+	if bestDurations != nil {
+		response.Junctions = make([]dto.JunctionDTO, len(junctions))
 		for i, junction := range junctions {
-			junction.SetOffset(int(bestOffsets[i]))
+			response.Junctions[i] = dto.JunctionToDTO(junction)
 		}
-		// Calculate green waves with optimized offsets
-		greenWaves := greenwave.FindGreenWaves(junctions, requestData.DesiredSpeedKmh)
-		throughGreenWaves := greenwave.MergeGreenWaves(greenWaves)
-
-		optimizerExtra := OptimizerExtra{}
-		switch opt := optimizer.(type) {
-		case *greenwave.OptimizerGenetic:
-			optimizerExtra.FitnessHistory = opt.BestFitnessHistory()
+	}
+	if paretoFront != nil {
+		response.ParetoFront = make([]dto.ParetoSolutionDTO, len(paretoFront))
+		for i, solution := range paretoFront {
+			response.ParetoFront[i] = dto.ParetoSolutionToDTO(solution)
 		}
+	}
+	return response, true
+}
+
+// prepareOptimizerOptions converts corridor and optimizer related request fields to extraction and common optimizer options.
+// Returns the seed of the random source too
+func prepareOptimizerOptions(junctions []*greenwave.Junction, requestData OptimizeRequest) ([]func(*greenwave.ExtractSettings), []func(*greenwave.OptimizerSettings), uint64, error) {
+	extractOptions, err := prepareExtractOptions(junctions, requestData.CorridorOptions)
+	if err != nil {
+		return nil, nil, 0, err
+	}
 
-		response := OptimizeResponse{
-			BestOffsets:       bestOffsets,
-			OptimizerExtra:    optimizerExtra,
-			GreenWaves:        convertGreenWavesToDTO(greenWaves),
-			ThroughGreenWaves: convertThroughGreenWavesToDTO(throughGreenWaves),
+	// Random seed fits into 53 bits, so it survives JSON round trip in any client
+	seed := rand.Uint64N(1 << 53)
+	if requestData.Seed != nil {
+		seed = *requestData.Seed
+	}
+
+	// Prepare common optimizer settings
+	settingsOptions := []func(*greenwave.OptimizerSettings){
+		greenwave.WithExtractOptions(extractOptions...),
+		greenwave.WithSeed(seed),
+	}
+	if requestData.TwoWay {
+		outboundWeight, inboundWeight := 1.0, 1.0
+		if requestData.OutboundWeight != nil {
+			outboundWeight = *requestData.OutboundWeight
+		}
+		if requestData.InboundWeight != nil {
+			inboundWeight = *requestData.InboundWeight
 		}
+		if outboundWeight < 0 || inboundWeight < 0 {
+			return nil, nil, 0, fmt.Errorf("direction weights must be non-negative")
+		}
+		settingsOptions = append(settingsOptions, greenwave.WithTwoWay(inboundSpeed(requestData.DesiredSpeedKmh, requestData.InboundSpeedKmh), outboundWeight, inboundWeight))
+	}
 
-		return ctx.JSON(200, response)
+	if requestData.OutputResolution < 0 {
+		return nil, nil, 0, fmt.Errorf("output resolution must be non-negative")
+	}
+	if requestData.OutputResolution > 0 {
+		settingsOptions = append(settingsOptions, greenwave.WithOutputResolution(requestData.OutputResolution))
 	}
-}
 
-// createOptimizer creates an optimizer based on the specified type and parameters
-func createOptimizer(optimizerType string, junctions []*greenwave.Junction, speedKmh float64, params map[string]interface{}) (greenwave.Optimizer, error) {
-	switch strings.ToLower(optimizerType) {
-	case "genetic":
-		return createGeneticOptimizer(junctions, speedKmh, params)
-	default:
-		return nil, fmt.Errorf("unsupported optimizer type: %s", optimizerType)
+	if requestData.TimeBudget < 0 {
+		return nil, nil, 0, fmt.Errorf("time budget must be non-negative")
+	}
+	if requestData.TimeBudget > 0 {
+		settingsOptions = append(settingsOptions, greenwave.WithTimeBudget(time.Duration(requestData.TimeBudget*float64(time.Second))))
+	}
+	if requestData.MaxStagnantGenerations < 0 {
+		return nil, nil, 0, fmt.Errorf("max stagnant generations must be non-negative")
+	}
+	if requestData.MaxStagnantGenerations > 0 {
+		settingsOptions = append(settingsOptions, greenwave.WithMaxStagnantGenerations(requestData.MaxStagnantGenerations))
+	}
+	if requestData.TargetFitness != nil {
+		settingsOptions = append(settingsOptions, greenwave.WithTargetFitness(*requestData.TargetFitness))
 	}
-}
 
-// createGeneticOptimizer creates a genetic algorithm optimizer with flexible parameters
-func createGeneticOptimizer(junctions []*greenwave.Junction, speedKmh float64, params map[string]interface{}) (greenwave.Optimizer, error) {
-	// Helper function to get parameter with default value
-	getParam := func(key string, defaultValue interface{}) interface{} {
-		if val, exists := params[key]; exists {
-			return val
-		}
-		return defaultValue
+	fitness, err := prepareFitness(requestData.OptimizerParams)
+	if err != nil {
+		return nil, nil, 0, err
 	}
+	settingsOptions = append(settingsOptions, greenwave.WithFitness(fitness))
 
-	// Helper function to convert interface{} to specific types with validation
-	getIntParam := func(key string, defaultValue int) (int, error) {
-		val := getParam(key, defaultValue)
-		switch v := val.(type) {
-		case int:
-			return v, nil
-		case float64:
-			return int(v), nil
-		default:
-			return defaultValue, nil
+	if requestData.GreenSplit != nil {
+		if strings.ToLower(requestData.OptimizerType) != "genetic" {
+			return nil, nil, 0, fmt.Errorf("green split optimization is supported by genetic optimizer only")
 		}
+		greenSplitMode, err := prepareGreenSplit(junctions, *requestData.GreenSplit)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		settingsOptions = append(settingsOptions, greenwave.WithGreenSplits(greenSplitMode))
 	}
+	return extractOptions, settingsOptions, seed, nil
+}
+
+// fitnessByName contains built-in fitness functions which could be selected via "fitness" optimizer parameter
+var fitnessByName = map[string]greenwave.FitnessFunc{
+	"depth_weighted": greenwave.DepthWeightedBandwidthFitness{},
+	"max_band":       greenwave.MaxBandFitness{},
+	"coverage":       greenwave.CoverageFitness{},
+	"stops":          greenwave.StopsFitness{},
+}
 
-	getFloatParam := func(key string, defaultValue float64) (float64, error) {
-		val := getParam(key, defaultValue)
-		switch v := val.(type) {
-		case float64:
-			return v, nil
-		case int:
-			return float64(v), nil
-		default:
-			return defaultValue, nil
+// prepareFitness returns the fitness function selected via "fitness" optimizer parameter.
+// Weighted combination ("weighted") takes weights of built-in fitness functions from "fitness_weights" parameter
+func prepareFitness(params optimizerParams) (greenwave.FitnessFunc, error) {
+	fitnessName := strings.ToLower(params.getString("fitness", "depth_weighted"))
+	if fitnessName != "weighted" {
+		fitness, ok := fitnessByName[fitnessName]
+		if !ok {
+			return nil, fmt.Errorf("unsupported fitness: %s", fitnessName)
 		}
+		return fitness, nil
+	}
+	weights, err := params.getParams("fitness_weights")
+	if err != nil {
+		return nil, fmt.Errorf("invalid fitness_weights parameter: %v", err)
+	}
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("fitness_weights must be provided for weighted fitness")
 	}
+	// Sort names, so the sum is the same for the same weights
+	names := make([]string, 0, len(weights))
+	for name := range weights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	terms := make(greenwave.WeightedFitness, 0, len(names))
+	for _, name := range names {
+		fitness, ok := fitnessByName[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported fitness in fitness_weights: %s", name)
+		}
+		weight, err := weights.getFloat(name, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight of %s fitness: %v", name, err)
+		}
+		terms = append(terms, greenwave.FitnessTerm{Fitness: fitness, Weight: weight})
+	}
+	return terms, nil
+}
 
-	getStringParam := func(key string, defaultValue string) string {
-		val := getParam(key, defaultValue)
-		if str, ok := val.(string); ok {
-			return str
+// prepareGreenSplit validates signal duration bounds and returns green split optimization mode
+func prepareGreenSplit(junctions []*greenwave.Junction, greenSplitDTO dto.GreenSplitDTO) (greenwave.GreenSplitMode, error) {
+	var mode greenwave.GreenSplitMode
+	switch strings.ToLower(greenSplitDTO.Mode) {
+	case "", "fixed_cycle":
+		mode = greenwave.GREEN_SPLIT_FIXED_CYCLE
+	case "free_cycle":
+		mode = greenwave.GREEN_SPLIT_FREE_CYCLE
+	default:
+		return mode, fmt.Errorf("unsupported green split mode: %s", greenSplitDTO.Mode)
+	}
+	for i, junction := range junctions {
+		if len(junction.GetSignalGroups()) > 0 {
+			return mode, fmt.Errorf("junction %d: green split optimization does not support signal groups", i)
+		}
+		for _, phase := range junction.Cycle {
+			for _, signal := range phase.Signals {
+				if signal.MinDuration < 0 || signal.MinDuration > signal.MaxDuration {
+					return mode, fmt.Errorf("junction %d: signal min duration must be non-negative and not greater than max duration", i)
+				}
+			}
 		}
-		return defaultValue
 	}
+	return mode, nil
+}
+
+// createOptimizer creates an optimizer based on the specified type and parameters
+func createOptimizer(optimizerType string, junctions []*greenwave.Junction, speedKmh float64, params map[string]interface{}, settingsOptions ...func(*greenwave.OptimizerSettings)) (greenwave.Optimizer, error) {
+	switch strings.ToLower(optimizerType) {
+	case "genetic":
+		return createGeneticOptimizer(junctions, speedKmh, params, settingsOptions...)
+	case "maxband":
+		return createMaxbandOptimizer(junctions, speedKmh, settingsOptions...)
+	case "dp":
+		return createDPOptimizer(junctions, speedKmh, params, settingsOptions...)
+	case "annealing":
+		return createAnnealingOptimizer(junctions, speedKmh, params, settingsOptions...)
+	case "pso":
+		return createPSOOptimizer(junctions, speedKmh, params, settingsOptions...)
+	case "nsga2":
+		return createNSGA2Optimizer(junctions, speedKmh, params, settingsOptions...)
+	default:
+		return nil, fmt.Errorf("unsupported optimizer type: %s", optimizerType)
+	}
+}
+
+// createGeneticOptimizer creates a genetic algorithm optimizer with flexible parameters
+func createGeneticOptimizer(junctions []*greenwave.Junction, speedKmh float64, params map[string]interface{}, settingsOptions ...func(*greenwave.OptimizerSettings)) (greenwave.Optimizer, error) {
+	getIntParam := optimizerParams(params).getInt
+	getFloatParam := optimizerParams(params).getFloat
+	getStringParam := optimizerParams(params).getString
 
 	// Extract parameters with defaults
 	populationSize, err := getIntParam("population_size", 50)
@@ -204,6 +511,11 @@ func createGeneticOptimizer(junctions []*greenwave.Junction, speedKmh float64, p
 		return nil, fmt.Errorf("invalid tournament_size parameter: %v", err)
 	}
 
+	workers, err := getIntParam("workers", 1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workers parameter: %v", err)
+	}
+
 	crossoverTypeStr := getStringParam("crossover_type", "blend")
 
 	// Parse crossover type
@@ -230,6 +542,9 @@ func createGeneticOptimizer(junctions []*greenwave.Junction, speedKmh float64, p
 	if tournamentSize <= 0 {
 		return nil, fmt.Errorf("tournament_size must be greater than 0")
 	}
+	if workers <= 0 {
+		return nil, fmt.Errorf("workers must be greater than 0")
+	}
 
 	return greenwave.NewOptimizerGenetic(
 		junctions,
@@ -239,5 +554,283 @@ func createGeneticOptimizer(junctions []*greenwave.Junction, speedKmh float64, p
 		mutationRate,
 		tournamentSize,
 		crossoverType,
+		append(settingsOptions, greenwave.WithWorkers(workers))...,
 	), nil
 }
+
+// createMaxbandOptimizer creates an exact MAXBAND optimizer. All junctions must share the same cycle length
+func createMaxbandOptimizer(junctions []*greenwave.Junction, speedKmh float64, settingsOptions ...func(*greenwave.OptimizerSettings)) (greenwave.Optimizer, error) {
+	if _, err := greenwave.CommonCycle(junctions); err != nil {
+		return nil, fmt.Errorf("maxband optimizer requires common cycle length: %w", err)
+	}
+	return greenwave.NewOptimizerMaxband(junctions, speedKmh, settingsOptions...), nil
+}
+
+// createDPOptimizer creates a dynamic programming optimizer over discretized offsets
+func createDPOptimizer(junctions []*greenwave.Junction, speedKmh float64, params map[string]interface{}, settingsOptions ...func(*greenwave.OptimizerSettings)) (greenwave.Optimizer, error) {
+	step, err := optimizerParams(params).getFloat("step", 1.0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid step parameter: %v", err)
+	}
+	chainCorrection := optimizerParams(params).getBool("chain_correction", false)
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be greater than 0")
+	}
+	return greenwave.NewOptimizerDP(junctions, speedKmh, step, chainCorrection, settingsOptions...), nil
+}
+
+// createAnnealingOptimizer creates a simulated annealing optimizer with flexible parameters
+func createAnnealingOptimizer(junctions []*greenwave.Junction, speedKmh float64, params map[string]interface{}, settingsOptions ...func(*greenwave.OptimizerSettings)) (greenwave.Optimizer, error) {
+	getIntParam := optimizerParams(params).getInt
+	getFloatParam := optimizerParams(params).getFloat
+	getStringParam := optimizerParams(params).getString
+
+	// Extract parameters with defaults
+	initialTemperature, err := getFloatParam("initial_temperature", 10.0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid initial_temperature parameter: %v", err)
+	}
+
+	coolingRate, err := getFloatParam("cooling_rate", 0.95)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cooling_rate parameter: %v", err)
+	}
+
+	temperatureSteps, err := getIntParam("temperature_steps", 100)
+	if err != nil {
+		return nil, fmt.Errorf("invalid temperature_steps parameter: %v", err)
+	}
+
+	iterationsPerTemperature, err := getIntParam("iterations_per_temperature", 20)
+	if err != nil {
+		return nil, fmt.Errorf("invalid iterations_per_temperature parameter: %v", err)
+	}
+
+	restarts, err := getIntParam("restarts", 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid restarts parameter: %v", err)
+	}
+
+	coolingScheduleStr := getStringParam("cooling_schedule", "geometric")
+
+	// Parse cooling schedule
+	var coolingSchedule greenwave.CoolingSchedule
+	switch strings.ToLower(coolingScheduleStr) {
+	case "geometric":
+		coolingSchedule = greenwave.COOLING_GEOMETRIC
+	case "linear":
+		coolingSchedule = greenwave.COOLING_LINEAR
+	case "adaptive":
+		coolingSchedule = greenwave.COOLING_ADAPTIVE
+	default:
+		return nil, fmt.Errorf("unsupported cooling schedule: %s", coolingScheduleStr)
+	}
+
+	// Validate parameters
+	if initialTemperature <= 0 {
+		return nil, fmt.Errorf("initial_temperature must be greater than 0")
+	}
+	if coolingRate <= 0 || coolingRate >= 1 {
+		return nil, fmt.Errorf("cooling_rate must be between 0 and 1 (exclusive)")
+	}
+	if temperatureSteps <= 0 {
+		return nil, fmt.Errorf("temperature_steps must be greater than 0")
+	}
+	if iterationsPerTemperature <= 0 {
+		return nil, fmt.Errorf("iterations_per_temperature must be greater than 0")
+	}
+	if restarts < 0 {
+		return nil, fmt.Errorf("restarts must be non-negative")
+	}
+
+	return greenwave.NewOptimizerAnnealing(
+		junctions,
+		speedKmh,
+		initialTemperature,
+		coolingSchedule,
+		coolingRate,
+		temperatureSteps,
+		iterationsPerTemperature,
+		restarts,
+		settingsOptions...,
+	), nil
+}
+
+// createPSOOptimizer creates a particle swarm optimizer with flexible parameters
+func createPSOOptimizer(junctions []*greenwave.Junction, speedKmh float64, params map[string]interface{}, settingsOptions ...func(*greenwave.OptimizerSettings)) (greenwave.Optimizer, error) {
+	getIntParam := optimizerParams(params).getInt
+	getFloatParam := optimizerParams(params).getFloat
+	getStringParam := optimizerParams(params).getString
+
+	// Extract parameters with defaults
+	swarmSize, err := getIntParam("swarm_size", 30)
+	if err != nil {
+		return nil, fmt.Errorf("invalid swarm_size parameter: %v", err)
+	}
+
+	iterations, err := getIntParam("iterations", 100)
+	if err != nil {
+		return nil, fmt.Errorf("invalid iterations parameter: %v", err)
+	}
+
+	inertia, err := getFloatParam("inertia", 0.7)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inertia parameter: %v", err)
+	}
+
+	cognitive, err := getFloatParam("cognitive", 1.5)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cognitive parameter: %v", err)
+	}
+
+	social, err := getFloatParam("social", 1.5)
+	if err != nil {
+		return nil, fmt.Errorf("invalid social parameter: %v", err)
+	}
+
+	topologyStr := getStringParam("topology", "global")
+
+	// Parse topology
+	var topology greenwave.SwarmTopology
+	switch strings.ToLower(topologyStr) {
+	case "global":
+		topology = greenwave.SWARM_TOPOLOGY_GLOBAL
+	case "ring":
+		topology = greenwave.SWARM_TOPOLOGY_RING
+	default:
+		return nil, fmt.Errorf("unsupported topology: %s", topologyStr)
+	}
+
+	// Validate parameters
+	if swarmSize <= 0 {
+		return nil, fmt.Errorf("swarm_size must be greater than 0")
+	}
+	if iterations <= 0 {
+		return nil, fmt.Errorf("iterations must be greater than 0")
+	}
+	if inertia < 0 || cognitive < 0 || social < 0 {
+		return nil, fmt.Errorf("inertia, cognitive and social coefficients must be non-negative")
+	}
+
+	return greenwave.NewOptimizerPSO(
+		junctions,
+		speedKmh,
+		swarmSize,
+		iterations,
+		inertia,
+		cognitive,
+		social,
+		topology,
+		settingsOptions...,
+	), nil
+}
+
+// createNSGA2Optimizer creates a multi-objective NSGA-II optimizer with flexible parameters
+func createNSGA2Optimizer(junctions []*greenwave.Junction, speedKmh float64, params map[string]interface{}, settingsOptions ...func(*greenwave.OptimizerSettings)) (greenwave.Optimizer, error) {
+	getIntParam := optimizerParams(params).getInt
+	getFloatParam := optimizerParams(params).getFloat
+
+	// Extract parameters with defaults
+	populationSize, err := getIntParam("population_size", 50)
+	if err != nil {
+		return nil, fmt.Errorf("invalid population_size parameter: %v", err)
+	}
+
+	generations, err := getIntParam("generations", 100)
+	if err != nil {
+		return nil, fmt.Errorf("invalid generations parameter: %v", err)
+	}
+
+	mutationRate, err := getFloatParam("mutation_rate", 0.1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mutation_rate parameter: %v", err)
+	}
+
+	// Validate parameters
+	if populationSize <= 0 {
+		return nil, fmt.Errorf("population_size must be greater than 0")
+	}
+	if generations <= 0 {
+		return nil, fmt.Errorf("generations must be greater than 0")
+	}
+	if mutationRate < 0 || mutationRate > 1 {
+		return nil, fmt.Errorf("mutation_rate must be between 0 and 1")
+	}
+
+	return greenwave.NewOptimizerNSGA2(
+		junctions,
+		speedKmh,
+		populationSize,
+		generations,
+		mutationRate,
+		settingsOptions...,
+	), nil
+}
+
+// optimizerParams is a set of optimizer parameters from the request
+type optimizerParams map[string]interface{}
+
+// get returns parameter with default value
+func (params optimizerParams) get(key string, defaultValue interface{}) interface{} {
+	if val, exists := params[key]; exists {
+		return val
+	}
+	return defaultValue
+}
+
+// getInt converts parameter to int
+func (params optimizerParams) getInt(key string, defaultValue int) (int, error) {
+	val := params.get(key, defaultValue)
+	switch v := val.(type) {
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	default:
+		return defaultValue, nil
+	}
+}
+
+// getFloat converts parameter to float64
+func (params optimizerParams) getFloat(key string, defaultValue float64) (float64, error) {
+	val := params.get(key, defaultValue)
+	switch v := val.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	default:
+		return defaultValue, nil
+	}
+}
+
+// getString converts parameter to string
+func (params optimizerParams) getString(key string, defaultValue string) string {
+	val := params.get(key, defaultValue)
+	if str, ok := val.(string); ok {
+		return str
+	}
+	return defaultValue
+}
+
+// getParams converts parameter to nested parameters. Returns nil if parameter is not presented
+func (params optimizerParams) getParams(key string) (optimizerParams, error) {
+	val, exists := params[key]
+	if !exists {
+		return nil, nil
+	}
+	nested, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("object is expected")
+	}
+	return optimizerParams(nested), nil
+}
+
+// getBool converts parameter to bool
+func (params optimizerParams) getBool(key string, defaultValue bool) bool {
+	val := params.get(key, defaultValue)
+	if b, ok := val.(bool); ok {
+		return b
+	}
+	return defaultValue
+}
@@ -0,0 +1,378 @@
+package rest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/LdDl/greenwave"
+	"github.com/LdDl/greenwave/app/configuration"
+	"github.com/LdDl/greenwave/app/rest/dto"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultJobWorkers   = 2
+	defaultJobQueueSize = 100
+	defaultJobResultTTL = 3600 // seconds
+)
+
+var (
+	// errJobNotFound is returned for unknown (or expired) jobs
+	errJobNotFound = errors.New("Job not found")
+	// errJobFinished is returned when finished job is cancelled
+	errJobFinished = errors.New("Job has been finished already")
+	// errJobQueueFull is returned when there are too many queued jobs
+	errJobQueueFull = errors.New("Job queue is full")
+)
+
+// JobStatus defines the state of the asynchronous optimization job
+type JobStatus uint8
+
+const (
+	// JOB_QUEUED means that the job waits for a free worker
+	JOB_QUEUED JobStatus = iota
+	// JOB_RUNNING means that the optimization is in progress
+	JOB_RUNNING
+	// JOB_DONE means that the optimization has been finished and the result is available
+	JOB_DONE
+	// JOB_FAILED means that the optimization has failed
+	JOB_FAILED
+	// JOB_CANCELLED means that the job has been cancelled
+	JOB_CANCELLED
+)
+
+var jobStatusToStr = [...]string{"queued", "running", "done", "failed", "cancelled"}
+
+// String returns the string representation of the JobStatus
+func (status JobStatus) String() string {
+	return jobStatusToStr[status]
+}
+
+// finished returns true if the job will not change anymore
+func (status JobStatus) finished() bool {
+	return status == JOB_DONE || status == JOB_FAILED || status == JOB_CANCELLED
+}
+
+// OptimizeJobResponse represents the state of the asynchronous optimization job.
+// swagger:model
+type OptimizeJobResponse struct {
+	// Identifier of the job
+	ID string `json:"id" example:"3f0c1e9a6b2d4c8e9f1a2b3c4d5e6f70"`
+	// Status of the job: queued, running, done, failed or cancelled
+	Status string `json:"status" example:"running"`
+	// Time when the job has been submitted
+	CreatedAt time.Time `json:"created_at"`
+	// Time when the optimization has been started
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// Time when the job has been finished (or cancelled)
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Latest progress of the optimization. Presented for genetic, annealing, pso and nsga2 optimizers once the first generation is done
+	Progress *dto.ProgressDTO `json:"progress,omitempty"`
+	// Result of the optimization. Presented only when the job is done
+	Result *OptimizeResponse `json:"result,omitempty"`
+	// Reason of the failure. Presented only when the job is failed
+	Error string `json:"error,omitempty"`
+}
+
+// optimizeJob is the optimization which runs in background
+type optimizeJob struct {
+	id         string
+	status     JobStatus
+	task       *optimization
+	ctx        context.Context
+	cancel     context.CancelFunc
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+	progress   *dto.ProgressDTO
+	result     *OptimizeResponse
+	err        string
+}
+
+// JobManager runs optimization jobs on the bounded pool of workers and keeps finished jobs for the given time
+type JobManager struct {
+	mu   sync.Mutex
+	jobs map[string]*optimizeJob
+	// queue contains jobs waiting for a free worker
+	queue chan *optimizeJob
+	// resultTTL is the time to keep finished jobs
+	resultTTL time.Duration
+}
+
+// NewJobManager creates a new instance of JobManager and starts its workers. Zero settings fall back to defaults
+func NewJobManager(cfg configuration.JobsConf) *JobManager {
+	workers, queueSize, resultTTL := cfg.Workers, cfg.QueueSize, cfg.ResultTTL
+	if workers <= 0 {
+		workers = defaultJobWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultJobQueueSize
+	}
+	if resultTTL <= 0 {
+		resultTTL = defaultJobResultTTL
+	}
+	manager := &JobManager{
+		jobs:      make(map[string]*optimizeJob),
+		queue:     make(chan *optimizeJob, queueSize),
+		resultTTL: time.Duration(resultTTL) * time.Second,
+	}
+	for i := 0; i < workers; i++ {
+		go manager.work()
+	}
+	go manager.cleanup()
+	return manager
+}
+
+// Submit validates the request and puts the optimization job into the queue
+func (manager *JobManager) Submit(requestData OptimizeRequest) (OptimizeJobResponse, error) {
+	id, err := newJobID()
+	if err != nil {
+		return OptimizeJobResponse{}, err
+	}
+	job := &optimizeJob{
+		id:        id,
+		status:    JOB_QUEUED,
+		createdAt: time.Now(),
+	}
+	// Keep the latest progress only
+	observer := greenwave.ObserverFunc(func(generationProgress greenwave.Progress) bool {
+		progress := dto.ProgressToDTO(generationProgress)
+		manager.mu.Lock()
+		if job.status == JOB_RUNNING {
+			job.progress = &progress
+		}
+		manager.mu.Unlock()
+		return true
+	})
+	job.task, err = prepareOptimization(requestData, observer)
+	if err != nil {
+		return OptimizeJobResponse{}, err
+	}
+	job.ctx, job.cancel = context.WithCancel(context.Background())
+
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	select {
+	case manager.queue <- job:
+	default:
+		job.cancel()
+		return OptimizeJobResponse{}, errJobQueueFull
+	}
+	manager.jobs[job.id] = job
+	return job.response(), nil
+}
+
+// Get returns the state of the job
+func (manager *JobManager) Get(id string) (OptimizeJobResponse, error) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	job, ok := manager.jobs[id]
+	if !ok {
+		return OptimizeJobResponse{}, errJobNotFound
+	}
+	return job.response(), nil
+}
+
+// Cancel cancels queued or running job. Running optimization stops as soon as possible, best offsets found so far are discarded
+func (manager *JobManager) Cancel(id string) (OptimizeJobResponse, error) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	job, ok := manager.jobs[id]
+	if !ok {
+		return OptimizeJobResponse{}, errJobNotFound
+	}
+	if job.status.finished() {
+		return job.response(), errJobFinished
+	}
+	job.cancel()
+	job.status = JOB_CANCELLED
+	job.finishedAt = time.Now()
+	job.task = nil
+	return job.response(), nil
+}
+
+// work runs queued jobs one by one
+func (manager *JobManager) work() {
+	for job := range manager.queue {
+		manager.mu.Lock()
+		if job.status != JOB_QUEUED {
+			// Cancelled while waiting in the queue
+			manager.mu.Unlock()
+			continue
+		}
+		job.status = JOB_RUNNING
+		job.startedAt = time.Now()
+		task := job.task
+		manager.mu.Unlock()
+
+		response, err := runJob(job.ctx, task)
+
+		manager.mu.Lock()
+		if job.status == JOB_RUNNING {
+			job.finishedAt = time.Now()
+			switch {
+			case err != nil:
+				job.status = JOB_FAILED
+				job.err = err.Error()
+			case response == nil:
+				job.status = JOB_CANCELLED
+			default:
+				job.status = JOB_DONE
+				job.result = response
+			}
+		}
+		job.task = nil
+		job.cancel()
+		manager.mu.Unlock()
+	}
+}
+
+// runJob runs the optimization. Returns nil response if the job has been cancelled
+func runJob(ctx context.Context, task *optimization) (response *OptimizeResponse, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Str("scope", "jobs").Interface("panic", r).Msg("Optimization has failed")
+			response, err = nil, fmt.Errorf("Optimization has failed: %v", r)
+		}
+	}()
+	response, ok := task.run(ctx)
+	if !ok || ctx.Err() != nil {
+		return nil, nil
+	}
+	return response, nil
+}
+
+// cleanup periodically removes jobs which have been finished earlier than the result TTL
+func (manager *JobManager) cleanup() {
+	ticker := time.NewTicker(min(manager.resultTTL, time.Minute))
+	defer ticker.Stop()
+	for now := range ticker.C {
+		manager.mu.Lock()
+		for id, job := range manager.jobs {
+			if job.status.finished() && now.Sub(job.finishedAt) > manager.resultTTL {
+				delete(manager.jobs, id)
+			}
+		}
+		manager.mu.Unlock()
+	}
+}
+
+// response returns the public state of the job. Must be called under the manager lock
+func (job *optimizeJob) response() OptimizeJobResponse {
+	response := OptimizeJobResponse{
+		ID:        job.id,
+		Status:    job.status.String(),
+		CreatedAt: job.createdAt,
+		Progress:  job.progress,
+		Result:    job.result,
+		Error:     job.err,
+	}
+	if !job.startedAt.IsZero() {
+		startedAt := job.startedAt
+		response.StartedAt = &startedAt
+	}
+	if !job.finishedAt.IsZero() {
+		finishedAt := job.finishedAt
+		response.FinishedAt = &finishedAt
+	}
+	return response
+}
+
+// newJobID returns random identifier of the job
+func newJobID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// CreateOptimizeJob submits the optimization job which runs in background.
+// @Summary Submit optimization job
+// @Description Validates the optimization request and puts it into the queue. Poll the job for the progress and the result
+// @Tags Optimize
+// @Produce json
+// @Param POST-body body rest.OptimizeRequest true "Traffic lights configuration"
+// @Success 202 {object} rest.OptimizeJobResponse
+// @Failure 400 {object} codes.Error400
+// @Failure 503 {object} codes.Error503
+// @Failure 500 {object} codes.Error500
+// @Router /api/greenwave/optimize/jobs [POST]
+func CreateOptimizeJob(manager *JobManager) func(ctx echo.Context) error {
+	return func(ctx echo.Context) error {
+		requestData, err := bindOptimizeRequest(ctx)
+		if err != nil {
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+		response, err := manager.Submit(requestData)
+		if err != nil {
+			if errors.Is(err, errJobQueueFull) {
+				return ctx.JSON(503, echo.Map{
+					"Error": err.Error(),
+				})
+			}
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+		return ctx.JSON(202, response)
+	}
+}
+
+// GetOptimizeJob returns the state of the optimization job.
+// @Summary Get optimization job
+// @Description Returns status and progress of the optimization job, result is returned when the job is done
+// @Tags Optimize
+// @Produce json
+// @Param id path string true "Job identifier"
+// @Success 200 {object} rest.OptimizeJobResponse
+// @Failure 404 {object} codes.Error404
+// @Failure 500 {object} codes.Error500
+// @Router /api/greenwave/optimize/jobs/{id} [GET]
+func GetOptimizeJob(manager *JobManager) func(ctx echo.Context) error {
+	return func(ctx echo.Context) error {
+		response, err := manager.Get(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(404, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+		return ctx.JSON(200, response)
+	}
+}
+
+// CancelOptimizeJob cancels the optimization job.
+// @Summary Cancel optimization job
+// @Description Cancels queued or running optimization job
+// @Tags Optimize
+// @Produce json
+// @Param id path string true "Job identifier"
+// @Success 200 {object} rest.OptimizeJobResponse
+// @Failure 404 {object} codes.Error404
+// @Failure 409 {object} codes.Error409
+// @Failure 500 {object} codes.Error500
+// @Router /api/greenwave/optimize/jobs/{id} [DELETE]
+func CancelOptimizeJob(manager *JobManager) func(ctx echo.Context) error {
+	return func(ctx echo.Context) error {
+		response, err := manager.Cancel(ctx.Param("id"))
+		switch {
+		case errors.Is(err, errJobNotFound):
+			return ctx.JSON(404, echo.Map{
+				"Error": err.Error(),
+			})
+		case errors.Is(err, errJobFinished):
+			return ctx.JSON(409, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+		return ctx.JSON(200, response)
+	}
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/LdDl/greenwave"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// OptimizeProgressEvent represents the payload of the "progress" event of the optimization stream.
+// swagger:model
+type OptimizeProgressEvent struct {
+	// Index of the generation (iteration, temperature step), starting from 0
+	Generation int `json:"generation"`
+	// Best fitness found so far
+	BestFitness float64 `json:"best_fitness"`
+	// Best offsets found so far
+	BestOffsets []float64 `json:"best_offsets"`
+	// Time in seconds since the optimization has been started
+	Elapsed float64 `json:"elapsed"`
+}
+
+// RequestOptimizeStream streams optimization progress for traffic lights configuration via Server-Sent Events.
+// @Summary Request optimization with live progress
+// @Description Requests the optimization of green waves and streams Server-Sent Events: "progress" event (rest.OptimizeProgressEvent) after each generation
+// @Description of genetic, annealing, pso and nsga2 optimizers and the final "result" event (rest.OptimizeResponse). Validation errors are returned as usual JSON before the stream starts
+// @Tags Optimize
+// @Produce text/event-stream
+// @Param POST-body body rest.OptimizeRequest true "Traffic lights configuration"
+// @Success 200 {object} rest.OptimizeResponse
+// @Failure 400 {object} codes.Error400
+// @Failure 500 {object} codes.Error500
+// @Router /api/greenwave/optimize/stream [POST]
+func RequestOptimizeStream() func(ctx echo.Context) error {
+	return func(ctx echo.Context) error {
+		requestData, err := bindOptimizeRequest(ctx)
+		if err != nil {
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+		var started time.Time
+		// Observer is called synchronously from the optimization, which runs in the handler goroutine, so it could write the response directly
+		observer := greenwave.ObserverFunc(func(progress greenwave.Progress) bool {
+			event := OptimizeProgressEvent{
+				Generation:  progress.Generation,
+				BestFitness: progress.BestFitness,
+				BestOffsets: progress.BestOffsets,
+				Elapsed:     time.Since(started).Seconds(),
+			}
+			// Abort the optimization if client has gone
+			return writeServerSentEvent(ctx, "progress", event) == nil
+		})
+		task, err := prepareOptimization(requestData, observer)
+		if err != nil {
+			return ctx.JSON(400, echo.Map{
+				"Error": err.Error(),
+			})
+		}
+
+		ctx.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+		ctx.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+		ctx.Response().Header().Set(echo.HeaderConnection, "keep-alive")
+		ctx.Response().WriteHeader(http.StatusOK)
+		ctx.Response().Flush()
+
+		started = time.Now()
+		response, ok := task.run(ctx.Request().Context())
+		if !ok || ctx.Request().Context().Err() != nil {
+			log.Warn().Str("scope", "api").Str("method", ctx.Request().Method).Str("route", ctx.Request().URL.Path).Msg("Optimization has been cancelled")
+			return nil // Client has gone, nobody to respond to
+		}
+		err = writeServerSentEvent(ctx, "result", response)
+		if err != nil {
+			log.Error().Err(err).Str("scope", "api").Str("method", ctx.Request().Method).Str("route", ctx.Request().URL.Path).Msg("Can't write optimization result")
+		}
+		return nil
+	}
+}
+
+// writeServerSentEvent writes the named event with JSON data and flushes it to the client
+func writeServerSentEvent(ctx echo.Context, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(ctx.Response(), "event: %s\ndata: %s\n\n", event, payload)
+	if err != nil {
+		return err
+	}
+	ctx.Response().Flush()
+	return nil
+}
@@ -0,0 +1,285 @@
+package greenwave
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// CycleScalingMode defines how signal durations of a junction are adjusted to the candidate cycle length
+type CycleScalingMode uint8
+
+const (
+	// CYCLE_SCALING_PROPORTIONAL scales every signal duration proportionally to the candidate cycle length
+	CYCLE_SCALING_PROPORTIONAL CycleScalingMode = iota
+	// CYCLE_SCALING_BOUNDS scales signal durations proportionally and then keeps them within MinDuration/MaxDuration bounds.
+	// Candidate cycle is skipped if it could not be reached within bounds
+	CYCLE_SCALING_BOUNDS
+)
+
+var cycleScalingModeToStr = [...]string{"proportional", "bounds"}
+
+// String returns the string representation of the CycleScalingMode
+func (mode CycleScalingMode) String() string {
+	return cycleScalingModeToStr[mode]
+}
+
+// CycleSearchSettings contains settings of the common cycle length search.
+type CycleSearchSettings struct {
+	// minCycle is the shortest candidate cycle length in seconds
+	minCycle float64
+	// maxCycle is the longest candidate cycle length in seconds
+	maxCycle float64
+	// step is the difference between consecutive candidates in seconds
+	step float64
+	// scaling defines how signal durations are adjusted to the candidate
+	scaling CycleScalingMode
+	// halfCycle contains indices of junctions which run at half of the common cycle
+	halfCycle map[int]struct{}
+	// twoWay enables evaluation of the inbound bandwidth too
+	twoWay bool
+	// inboundSpeedKmh is the speed in kilometers per hour for the inbound direction
+	inboundSpeedKmh float64
+	// extractOptions are passed to green waves extraction during bandwidth evaluation
+	extractOptions []func(*ExtractSettings)
+}
+
+// newCycleSearchSettings creates settings with defaults (60-150 s range with 5 s step, proportional scaling) and applies provided options.
+func newCycleSearchSettings(options ...func(*CycleSearchSettings)) *CycleSearchSettings {
+	settings := &CycleSearchSettings{
+		minCycle:  60,
+		maxCycle:  150,
+		step:      5,
+		scaling:   CYCLE_SCALING_PROPORTIONAL,
+		halfCycle: make(map[int]struct{}),
+	}
+	for _, option := range options {
+		option(settings)
+	}
+	return settings
+}
+
+// WithCycleRange is an option function that sets candidate cycle lengths: from minCycle to maxCycle (inclusive) with the given step in seconds.
+func WithCycleRange(minCycle, maxCycle, step float64) func(*CycleSearchSettings) {
+	return func(s *CycleSearchSettings) {
+		s.minCycle = minCycle
+		s.maxCycle = maxCycle
+		s.step = step
+	}
+}
+
+// WithCycleScaling is an option function that sets how signal durations are adjusted to the candidate cycle length.
+func WithCycleScaling(mode CycleScalingMode) func(*CycleSearchSettings) {
+	return func(s *CycleSearchSettings) {
+		s.scaling = mode
+	}
+}
+
+// WithHalfCycleJunctions is an option function that makes junctions with the given indices run at half of the common cycle (double cycling).
+func WithHalfCycleJunctions(indices ...int) func(*CycleSearchSettings) {
+	return func(s *CycleSearchSettings) {
+		for _, idx := range indices {
+			s.halfCycle[idx] = struct{}{}
+		}
+	}
+}
+
+// WithCycleSearchTwoWay is an option function that enables evaluation of the inbound bandwidth too.
+func WithCycleSearchTwoWay(inboundSpeedKmh float64) func(*CycleSearchSettings) {
+	return func(s *CycleSearchSettings) {
+		s.twoWay = true
+		s.inboundSpeedKmh = inboundSpeedKmh
+	}
+}
+
+// WithCycleSearchExtractOptions is an option function that sets green waves extraction options (e.g. WithSegments) used during bandwidth evaluation.
+func WithCycleSearchExtractOptions(options ...func(*ExtractSettings)) func(*CycleSearchSettings) {
+	return func(s *CycleSearchSettings) {
+		s.extractOptions = append(s.extractOptions, options...)
+	}
+}
+
+// CycleCandidate is the result of offsets optimization for a single candidate cycle length.
+type CycleCandidate struct {
+	// Cycle is the common cycle length in seconds
+	Cycle float64
+	// Offsets contains optimal offsets of junctions for the cycle
+	Offsets []float64
+	// Durations contains scaled signal durations of each junction in cycle order (see Junction.GetSignalDurations)
+	Durations [][]float64
+	// OutboundBandwidth is the bandwidth of the widest outbound through green wave which passes the whole corridor
+	OutboundBandwidth float64
+	// InboundBandwidth is the bandwidth of the widest inbound through green wave which passes the whole corridor (two-way search only)
+	InboundBandwidth float64
+	// Efficiency is the bandwidth share of the cycle (averaged over directions)
+	Efficiency float64
+}
+
+// CycleSearchResult contains the recommended common cycle length and the bandwidth-vs-cycle curve.
+type CycleSearchResult struct {
+	// Recommended is the candidate with the highest efficiency (the shortest cycle wins ties)
+	Recommended *CycleCandidate
+	// Curve contains every feasible candidate in ascending order of cycle length
+	Curve []*CycleCandidate
+}
+
+// SearchCycleLength searches for the common cycle length of the coordinated corridor.
+// For each candidate cycle every junction cycle is scaled to it (see CycleScalingMode), offsets are optimized by the optimizer
+// created by newOptimizer for scaled copies of junctions and the bandwidth of the resulting plan is evaluated.
+// Error returned by newOptimizer stops the search and is returned as is.
+// Given junctions are not modified. Signal groups timelines follow scaled phase signals (see Junction.scaleCycle).
+func SearchCycleLength(junctions []*Junction, desiredSpeedKmh float64, newOptimizer func(junctions []*Junction) (Optimizer, error), options ...func(*CycleSearchSettings)) (*CycleSearchResult, error) {
+	return SearchCycleLengthContext(context.Background(), junctions, desiredSpeedKmh, newOptimizer, options...)
+}
+
+// SearchCycleLengthContext is the same as SearchCycleLength, but the search stops as soon as the context is cancelled:
+// context-aware optimizers (see ContextOptimizer) are interrupted, remaining candidates are skipped and the context error is returned.
+func SearchCycleLengthContext(ctx context.Context, junctions []*Junction, desiredSpeedKmh float64, newOptimizer func(junctions []*Junction) (Optimizer, error), options ...func(*CycleSearchSettings)) (*CycleSearchResult, error) {
+	settings := newCycleSearchSettings(options...)
+	if len(junctions) < 2 {
+		return nil, fmt.Errorf("at least 2 junctions are required")
+	}
+	if settings.minCycle <= 0 || settings.maxCycle < settings.minCycle || settings.step <= 0 {
+		return nil, fmt.Errorf("invalid cycle range: min cycle must be positive, max cycle must not be less than min cycle and step must be positive")
+	}
+	result := &CycleSearchResult{
+		Curve: make([]*CycleCandidate, 0),
+	}
+	candidatesNum := int(math.Floor((settings.maxCycle-settings.minCycle)/settings.step+eps)) + 1
+	for k := 0; k < candidatesNum; k++ {
+		cycle := settings.minCycle + float64(k)*settings.step
+		scaled, ok := settings.scaleJunctions(junctions, cycle)
+		if !ok {
+			continue // Candidate could not be reached within signal bounds
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		optimizer, err := newOptimizer(scaled)
+		if err != nil {
+			return nil, err
+		}
+		var offsets []float64
+		if contextOptimizer, ok := optimizer.(ContextOptimizer); ok {
+			optimizeResult := contextOptimizer.OptimizeContext(ctx)
+			if optimizeResult.StopReason == STOP_CANCELLED {
+				return nil, ctx.Err()
+			}
+			offsets = optimizeResult.Offsets
+		} else {
+			offsets = optimizer.Optimize()
+		}
+		candidate := &CycleCandidate{
+			Cycle:     cycle,
+			Offsets:   offsets,
+			Durations: make([][]float64, len(scaled)),
+		}
+		for i, junction := range scaled {
+			junction.SetOffset(offsets[i])
+			candidate.Durations[i] = junction.GetSignalDurations()
+		}
+		candidate.OutboundBandwidth = corridorBandwidth(MergeGreenWaves(FindGreenWaves(scaled, desiredSpeedKmh, settings.extractOptions...)), len(scaled))
+		candidate.Efficiency = candidate.OutboundBandwidth / cycle
+		if settings.twoWay {
+			candidate.InboundBandwidth = corridorBandwidth(MergeGreenWaves(FindGreenWavesInbound(scaled, settings.inboundSpeedKmh, settings.extractOptions...)), len(scaled))
+			candidate.Efficiency = (candidate.OutboundBandwidth + candidate.InboundBandwidth) / (2 * cycle)
+		}
+		result.Curve = append(result.Curve, candidate)
+		if result.Recommended == nil || candidate.Efficiency > result.Recommended.Efficiency+1e-9 {
+			result.Recommended = candidate
+		}
+	}
+	if result.Recommended == nil {
+		return nil, fmt.Errorf("no candidate cycle length could be reached within signal duration bounds")
+	}
+	return result, nil
+}
+
+// scaleJunctions returns copies of junctions with cycles scaled to the candidate cycle length (or its half for double cycling junctions).
+// Returns false if some junction could not reach its cycle length within signal bounds.
+func (s *CycleSearchSettings) scaleJunctions(junctions []*Junction, cycle float64) ([]*Junction, bool) {
+	scaled := make([]*Junction, len(junctions))
+	for i, junction := range junctions {
+		junctionCycle := cycle
+		if _, ok := s.halfCycle[i]; ok {
+			junctionCycle = cycle / 2
+		}
+		scaled[i] = junction.clone()
+		if !scaled[i].scaleCycle(junctionCycle, s.scaling) {
+			return nil, false
+		}
+	}
+	return scaled, true
+}
+
+// scaleCycle scales signal durations of the junction to the given cycle length.
+// Signal groups timelines are derived from the scaled phase signals: time within each phase signal is stretched as the signal itself,
+// so changes of groups stay aligned with changes of phase signals in bounds mode too.
+// Returns false if the cycle length could not be reached within signal bounds.
+func (jun *Junction) scaleCycle(cycle float64, mode CycleScalingMode) bool {
+	if jun.totalDuration <= 0 {
+		return false
+	}
+	factor := cycle / jun.totalDuration
+	original := jun.GetSignalDurations()
+	durations := make([]float64, len(original))
+	for i := range durations {
+		durations[i] = original[i] * factor
+	}
+	if mode == CYCLE_SCALING_BOUNDS {
+		split := newGreenSplit(jun, GREEN_SPLIT_FIXED_CYCLE)
+		minCycle, maxCycle := 0.0, 0.0
+		for i := range durations {
+			minCycle += split.minDurations[i]
+			maxCycle += split.maxDurations[i]
+		}
+		if cycle < minCycle-eps || cycle > maxCycle+eps {
+			return false
+		}
+		split.cycle = cycle
+		split.repair(durations)
+	}
+	jun.SetSignalDurations(durations)
+	for _, group := range jun.signalGroups {
+		start, groupSeconds := 0.0, 0.0
+		for _, signal := range group.Signals {
+			end := start + signal.Duration
+			signal.Duration = warpTime(end, original, durations) - warpTime(start, original, durations)
+			start = end
+			groupSeconds += signal.Duration
+		}
+		group.totalSeconds = groupSeconds
+	}
+	return true
+}
+
+// warpTime maps the time since the cycle start from the timeline of the given signal durations to the timeline of adjusted durations.
+// Time within a signal is stretched proportionally to the change of its duration. Time beyond the cycle is stretched as the whole cycle
+func warpTime(t float64, from, to []float64) float64 {
+	fromStart, toStart := 0.0, 0.0
+	for i := range from {
+		if t <= fromStart+from[i] {
+			if from[i] <= 0 {
+				return toStart
+			}
+			return toStart + (t-fromStart)*to[i]/from[i]
+		}
+		fromStart += from[i]
+		toStart += to[i]
+	}
+	if fromStart <= 0 {
+		return t
+	}
+	return toStart + (t-fromStart)*toStart/fromStart
+}
+
+// corridorBandwidth returns the bandwidth of the widest through green wave which passes all junctions of the corridor.
+func corridorBandwidth(throughGreenWaves []*ThroughGreenWave, junctionsNum int) float64 {
+	bandwidth := 0.0
+	for _, wave := range throughGreenWaves {
+		if wave.Depth() == junctionsNum && wave.Bandwidth() > bandwidth {
+			bandwidth = wave.Bandwidth()
+		}
+	}
+	return bandwidth
+}
@@ -0,0 +1,78 @@
+package greenwave
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LdDl/greenwave/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchCycleLength(t *testing.T) {
+	junctions := basicTestJuntions()
+	newOptimizer := func(scaled []*Junction) (Optimizer, error) {
+		return NewOptimizerGenetic(scaled, 40, 20, 10, 0.1, 3, CROSSOVER_BLEND), nil
+	}
+	result, err := SearchCycleLength(junctions, 40, newOptimizer, WithCycleRange(60, 100, 20))
+	assert.NoError(t, err, "Search should not fail")
+	assert.Equal(t, 3, len(result.Curve), "Expected candidate for each cycle length")
+	for i, candidate := range result.Curve {
+		assert.InDelta(t, 60.0+20*float64(i), candidate.Cycle, 1e-9, "Candidate cycle mismatch")
+		assert.Equal(t, len(junctions), len(candidate.Offsets), "Expected offset for each junction")
+		for j, durations := range candidate.Durations {
+			total := 0.0
+			for _, duration := range durations {
+				total += duration
+			}
+			assert.InDelta(t, candidate.Cycle, total, 1e-9, "Junction %d should be scaled to the candidate cycle", j)
+		}
+		assert.InDelta(t, candidate.OutboundBandwidth/candidate.Cycle, candidate.Efficiency, 1e-9, "Efficiency mismatch")
+		assert.LessOrEqual(t, candidate.Efficiency, result.Recommended.Efficiency+1e-9, "Recommended candidate should have the highest efficiency")
+	}
+	for _, junction := range junctions {
+		assert.InDelta(t, 85.0, junction.GetTotalDuration(), 1e-9, "Source junctions should not be modified")
+	}
+
+	// Bounded scaling skips candidates which could not be reached within signal bounds
+	result, err = SearchCycleLength(greenSplitTestJunctions(), 40, newOptimizer, WithCycleRange(60, 90, 5), WithCycleScaling(CYCLE_SCALING_BOUNDS))
+	assert.NoError(t, err, "Search should not fail")
+	for _, candidate := range result.Curve {
+		assert.GreaterOrEqual(t, candidate.Cycle, 75.0, "Candidate is out of signal bounds")
+	}
+	_, err = SearchCycleLength(greenSplitTestJunctions(), 40, newOptimizer, WithCycleRange(60, 70, 5), WithCycleScaling(CYCLE_SCALING_BOUNDS))
+	assert.Error(t, err, "Expected error when no candidate is feasible")
+}
+
+func TestSearchCycleLengthContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	created := 0
+	newOptimizer := func(scaled []*Junction) (Optimizer, error) {
+		created++
+		cancel() // Client has gone during the first candidate
+		return NewOptimizerGenetic(scaled, 40, 20, 10, 0.1, 3, CROSSOVER_BLEND), nil
+	}
+	_, err := SearchCycleLengthContext(ctx, basicTestJuntions(), 40, newOptimizer, WithCycleRange(60, 100, 20))
+	assert.ErrorIs(t, err, context.Canceled, "Cancelled search should return context error")
+	assert.Equal(t, 1, created, "Remaining candidates should be skipped")
+}
+
+func TestScaleCycleSignalGroups(t *testing.T) {
+	junction := NewJunction([]*Phase{
+		NewPhase(0, []*Signal{
+			NewSignal(30, color.GREEN, WithMinDuration(20), WithMaxDuration(40)),
+			NewSignal(30, color.RED),
+		}),
+	}, WithSignalGroups(
+		NewSignalGroup("main", []*Signal{NewSignal(30, color.GREEN), NewSignal(30, color.RED)}),
+		NewSignalGroup("turn", []*Signal{NewSignal(10, color.RED), NewSignal(20, color.GREEN), NewSignal(30, color.RED)}),
+	))
+	scaled := junction.clone()
+	assert.True(t, scaled.scaleCycle(70, CYCLE_SCALING_BOUNDS), "Cycle should be reachable within bounds")
+	assert.InDeltaSlice(t, []float64{40, 30}, scaled.GetSignalDurations(), 1e-9, "Only the bounded signal should be stretched")
+	main, turn := scaled.signalGroups[0], scaled.signalGroups[1]
+	assert.InDeltaSlice(t, []float64{40, 30}, []float64{main.Signals[0].Duration, main.Signals[1].Duration}, 1e-9, "Group timeline should follow phase signals")
+	assert.InDeltaSlice(t, []float64{40.0 / 3, 80.0 / 3, 30}, []float64{turn.Signals[0].Duration, turn.Signals[1].Duration, turn.Signals[2].Duration}, 1e-9, "Group changes within the phase signal should be stretched as the signal")
+	assert.InDelta(t, 70.0, main.totalSeconds, 1e-9, "Group timeline should match the cycle")
+	assert.InDelta(t, 70.0, turn.totalSeconds, 1e-9, "Group timeline should match the cycle")
+	assert.InDelta(t, 30.0, junction.signalGroups[0].Signals[0].Duration, 1e-9, "Source junction should not be modified")
+}
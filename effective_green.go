@@ -0,0 +1,75 @@
+package greenwave
+
+import "github.com/LdDl/greenwave/color"
+
+// EffectiveGreen defines which part of the signal timeline is actually usable by vehicles.
+type EffectiveGreen struct {
+	// StartupLostTime is the time in seconds lost at the beginning of green due vehicles start-up
+	StartupLostTime float64
+	// YellowExtension is the time in seconds of the following YELLOW signal which is still used by drivers
+	YellowExtension float64
+	// UseRedYellow enables counting REDYELLOW signal as usable time
+	UseRedYellow bool
+	// UseGreenRight enables counting GREENRIGHT signal as usable time
+	UseGreenRight bool
+}
+
+// NewEffectiveGreen creates a new EffectiveGreen instance with the specified start-up lost time and yellow extension.
+func NewEffectiveGreen(startupLostTime, yellowExtension float64, options ...func(*EffectiveGreen)) *EffectiveGreen {
+	effectiveGreen := &EffectiveGreen{
+		StartupLostTime: startupLostTime,
+		YellowExtension: yellowExtension,
+	}
+	for _, option := range options {
+		option(effectiveGreen)
+	}
+	return effectiveGreen
+}
+
+// WithRedYellowUsage is an option function that enables counting REDYELLOW signal as usable time.
+func WithRedYellowUsage() func(*EffectiveGreen) {
+	return func(eg *EffectiveGreen) {
+		eg.UseRedYellow = true
+	}
+}
+
+// WithGreenRightUsage is an option function that enables counting GREENRIGHT signal as usable time.
+func WithGreenRightUsage() func(*EffectiveGreen) {
+	return func(eg *EffectiveGreen) {
+		eg.UseGreenRight = true
+	}
+}
+
+// IsUsable checks if the signal color counts as usable (green) time.
+// Policy might be nil: only GREEN and GREENPRIORITY are usable then.
+func (eg *EffectiveGreen) IsUsable(c color.Color) bool {
+	switch c {
+	case color.GREEN, color.GREENPRIORITY:
+		return true
+	case color.REDYELLOW:
+		return eg != nil && eg.UseRedYellow
+	case color.GREENRIGHT:
+		return eg != nil && eg.UseGreenRight
+	default:
+		return false
+	}
+}
+
+// startupLostTime returns start-up lost time. Policy might be nil: zero is returned then.
+func (eg *EffectiveGreen) startupLostTime() float64 {
+	if eg == nil {
+		return 0
+	}
+	return eg.StartupLostTime
+}
+
+// yellowExtension returns usable part of the yellow signal with the given duration. Policy might be nil: zero is returned then.
+func (eg *EffectiveGreen) yellowExtension(yellowDuration float64) float64 {
+	if eg == nil || eg.YellowExtension <= 0 {
+		return 0
+	}
+	if eg.YellowExtension > yellowDuration {
+		return yellowDuration
+	}
+	return eg.YellowExtension
+}
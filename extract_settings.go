@@ -0,0 +1,124 @@
+package greenwave
+
+import "math"
+
+// ExtractSettings contains optional settings for green waves extraction.
+type ExtractSettings struct {
+	// segments contains corridor links between consecutive junctions (in outbound order)
+	segments []*Segment
+	// speedRange enables speed-robust bandwidth evaluation
+	speedRange bool
+	// minSpeedKmh is the lower bound of the speed range in km/h
+	minSpeedKmh float64
+	// maxSpeedKmh is the upper bound of the speed range in km/h
+	maxSpeedKmh float64
+	// effectiveGreen is the corridor effective green policy for junctions without own policy
+	effectiveGreen *EffectiveGreen
+	// outboundGroups contains names of coordinated signal groups per junction (in outbound order) for the outbound direction
+	outboundGroups []string
+	// inboundGroups contains names of coordinated signal groups per junction (in outbound order) for the inbound direction
+	inboundGroups []string
+	// maxHyperperiod is the upper bound of the corridor hyperperiod in seconds
+	maxHyperperiod float64
+}
+
+// newExtractSettings creates default settings and applies provided options.
+func newExtractSettings(options ...func(*ExtractSettings)) *ExtractSettings {
+	settings := &ExtractSettings{
+		maxHyperperiod: DefaultMaxHyperperiod,
+	}
+	for _, option := range options {
+		option(settings)
+	}
+	return settings
+}
+
+// WithSegments is an option function that sets corridor links between consecutive junctions.
+// Segments must be given in outbound order: segments[i] connects junctions[i] and junctions[i+1].
+// Missing (nil) segments fall back to straight line distance between junctions and desired speed.
+func WithSegments(segments []*Segment) func(*ExtractSettings) {
+	return func(s *ExtractSettings) {
+		s.segments = segments
+	}
+}
+
+// WithSpeedRange is an option function that enables speed-robust bandwidth evaluation.
+// Through green waves then report the band which remains valid for every constant speed within [minSpeedKmh, maxSpeedKmh] (see ThroughGreenWave.RobustBandwidth).
+func WithSpeedRange(minSpeedKmh, maxSpeedKmh float64) func(*ExtractSettings) {
+	return func(s *ExtractSettings) {
+		s.speedRange = true
+		s.minSpeedKmh = minSpeedKmh
+		s.maxSpeedKmh = maxSpeedKmh
+	}
+}
+
+// SpeedRangeFromDistribution returns the central speed range which covers given share (0; 1) of normally distributed speeds.
+// E.g. coverage 0.85 gives range between 7.5th and 92.5th percentiles. Result could be used with WithSpeedRange.
+func SpeedRangeFromDistribution(meanSpeedKmh, stdDevKmh, coverage float64) (minSpeedKmh float64, maxSpeedKmh float64) {
+	z := math.Sqrt2 * math.Erfinv(coverage)
+	minSpeedKmh = meanSpeedKmh - z*stdDevKmh
+	maxSpeedKmh = meanSpeedKmh + z*stdDevKmh
+	return minSpeedKmh, maxSpeedKmh
+}
+
+// WithCorridorEffectiveGreen is an option function that sets the effective green policy for junctions which have no own policy (see WithEffectiveGreen).
+func WithCorridorEffectiveGreen(effectiveGreen *EffectiveGreen) func(*ExtractSettings) {
+	return func(s *ExtractSettings) {
+		s.effectiveGreen = effectiveGreen
+	}
+}
+
+// WithCoordinatedSignalGroups is an option function that picks coordinated signal group of each junction per direction.
+// Both slices are indexed by junction position in outbound order. Empty (or missing) name means the phases timeline of the junction.
+func WithCoordinatedSignalGroups(outbound, inbound []string) func(*ExtractSettings) {
+	return func(s *ExtractSettings) {
+		s.outboundGroups = outbound
+		s.inboundGroups = inbound
+	}
+}
+
+// WithMaxHyperperiod is an option function that limits the corridor hyperperiod (see Hyperperiod) in seconds.
+// If the least common multiple of cycle lengths exceeds the limit then each junction is analysed over its own cycle only.
+func WithMaxHyperperiod(seconds float64) func(*ExtractSettings) {
+	return func(s *ExtractSettings) {
+		s.maxHyperperiod = seconds
+	}
+}
+
+// hyperperiod returns the period over which green intervals of the given junctions should be unrolled.
+// Zero means that the hyperperiod exceeds the limit and each junction should be analysed over its own cycle.
+func (s *ExtractSettings) hyperperiod(junctions []*Junction) float64 {
+	hyperperiod, ok := hyperperiodWithin(junctions, s.hyperperiodLimit())
+	if !ok {
+		return 0
+	}
+	return hyperperiod
+}
+
+// hyperperiodLimit returns the upper bound of the corridor hyperperiod in seconds. Non-positive limit means no limit
+func (s *ExtractSettings) hyperperiodLimit() float64 {
+	if s.maxHyperperiod <= 0 {
+		return math.Inf(1)
+	}
+	return s.maxHyperperiod
+}
+
+// signalGroup returns the name of the coordinated signal group for the junction with the given outbound index and direction.
+func (s *ExtractSettings) signalGroup(junctionIdx int, inbound bool) string {
+	groups := s.outboundGroups
+	if inbound {
+		groups = s.inboundGroups
+	}
+	if junctionIdx < 0 || junctionIdx >= len(groups) {
+		return ""
+	}
+	return groups[junctionIdx]
+}
+
+// segment returns the segment with the given outbound index or nil if it is not defined.
+func (s *ExtractSettings) segment(idx int) *Segment {
+	if idx < 0 || idx >= len(s.segments) {
+		return nil
+	}
+	return s.segments[idx]
+}
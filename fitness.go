@@ -0,0 +1,140 @@
+package greenwave
+
+import "math"
+
+// FitnessFunc evaluates green waves of a single direction of the corridor for the given signal plan. Higher value is better.
+// In two-way mode outbound and inbound values are combined using direction weights (see WithTwoWay).
+// It is called concurrently when the population is evaluated by several workers (see WithWorkers), so implementations must be safe for concurrent use.
+type FitnessFunc interface {
+	// Evaluate returns the fitness of green waves of a single direction
+	Evaluate(corridor *CorridorWaves) float64
+}
+
+// CorridorWaves contains green waves of a single direction of the corridor which are passed to FitnessFunc
+type CorridorWaves struct {
+	// SegmentsWaves contains green waves of each segment in travel order
+	SegmentsWaves [][]*GreenWave
+	// ThroughWaves contains through green waves (see MergeGreenWaves)
+	ThroughWaves []*ThroughGreenWave
+	// junctions are in travel order with the evaluated plan applied
+	junctions []*Junction
+	// settings are extraction settings green waves have been found with
+	settings *ExtractSettings
+	// inbound is true for the inbound direction
+	inbound bool
+}
+
+// newCorridorWaves finds green waves of the given direction. Junctions are given in outbound order
+func newCorridorWaves(junctions []*Junction, speedKmh float64, settings *ExtractSettings, inbound bool) *CorridorWaves {
+	if inbound {
+		junctions = reverseJunctions(junctions)
+	}
+	segmentsWaves := findGreenWaves(junctions, speedKmh, settings, inbound)
+	return &CorridorWaves{
+		SegmentsWaves: segmentsWaves,
+		ThroughWaves:  MergeGreenWaves(segmentsWaves),
+		junctions:     junctions,
+		settings:      settings,
+		inbound:       inbound,
+	}
+}
+
+// JunctionsNum returns the number of junctions of the corridor
+func (corridor *CorridorWaves) JunctionsNum() int {
+	return len(corridor.junctions)
+}
+
+// GreenTime returns the total duration in seconds of green intervals of the junction (with the given index in travel order)
+// within the corridor hyperperiod. Effective green policy and coordinated signal group of the direction are considered.
+func (corridor *CorridorWaves) GreenTime(junctionIdx int) float64 {
+	junctionOutboundIdx := junctionIdx
+	if corridor.inbound {
+		junctionOutboundIdx = len(corridor.junctions) - 1 - junctionIdx
+	}
+	intervals := adjustIntervalsByOffset(corridor.junctions[junctionIdx], corridor.settings.effectiveGreen, corridor.settings.signalGroup(junctionOutboundIdx, corridor.inbound), corridor.settings.hyperperiod(corridor.junctions))
+	greenTime := 0.0
+	for _, interval := range intervals {
+		greenTime += interval.End - interval.Start
+	}
+	return greenTime
+}
+
+// DepthWeightedBandwidthFitness is the sum of bandwidths of through green waves weighted by the squared ratio of the wave depth to the number of junctions.
+// It is the default fitness. Note that it rewards many overlapping short waves as well as a single long one.
+type DepthWeightedBandwidthFitness struct{}
+
+// Evaluate returns the sum of depth weighted bandwidths of through green waves
+func (DepthWeightedBandwidthFitness) Evaluate(corridor *CorridorWaves) float64 {
+	return throughWavesFitness(corridor.ThroughWaves, corridor.JunctionsNum())
+}
+
+// MaxBandFitness is the bandwidth in seconds of the widest through green wave which passes every junction of the corridor (as MAXBAND maximizes)
+type MaxBandFitness struct{}
+
+// Evaluate returns the bandwidth of the widest through green wave which passes every junction
+func (MaxBandFitness) Evaluate(corridor *CorridorWaves) float64 {
+	return corridorBandwidth(corridor.ThroughWaves, corridor.JunctionsNum())
+}
+
+// CoverageFitness is the ratio of corridor segments which have at least one green wave: from 0 (no waves) to 1 (every segment is covered)
+type CoverageFitness struct{}
+
+// Evaluate returns the ratio of covered segments
+func (CoverageFitness) Evaluate(corridor *CorridorWaves) float64 {
+	return segmentsCoverage(corridor.SegmentsWaves, corridor.JunctionsNum()-1)
+}
+
+// StopsFitness is the estimated number of stops of a vehicle travelling the whole corridor taken with minus sign (so fewer stops is better).
+// Vehicles are assumed to pass each junction uniformly during its green time: a vehicle which does not fit into green waves of
+// the segment stops at the next junction. So the estimated number of stops is the sum over segments of the share of green time
+// of the upstream junction which is not covered by green waves.
+type StopsFitness struct{}
+
+// Evaluate returns minus estimated number of stops
+func (StopsFitness) Evaluate(corridor *CorridorWaves) float64 {
+	stops := 0.0
+	for i, waves := range corridor.SegmentsWaves {
+		greenTime := corridor.GreenTime(i)
+		if greenTime <= eps {
+			continue // Nobody passes the upstream junction
+		}
+		passed := 0.0
+		for _, wave := range waves {
+			passed += wave.Bandwidth()
+		}
+		stops += 1 - math.Min(1, passed/greenTime)
+	}
+	return -stops
+}
+
+// FitnessTerm is the fitness with its weight in WeightedFitness
+type FitnessTerm struct {
+	// Fitness is the fitness to combine
+	Fitness FitnessFunc
+	// Weight is the multiplier of the fitness
+	Weight float64
+}
+
+// WeightedFitness is the weighted sum of several fitness functions. Keep in mind that fitness values have different scales:
+// bandwidths are in seconds, coverage is within [0; 1], stops are within [-segments; 0].
+type WeightedFitness []FitnessTerm
+
+// Evaluate returns the weighted sum of fitness values
+func (terms WeightedFitness) Evaluate(corridor *CorridorWaves) float64 {
+	fitness := 0.0
+	for _, term := range terms {
+		fitness += term.Weight * term.Fitness.Evaluate(corridor)
+	}
+	return fitness
+}
+
+// WithFitness is an option function that sets the fitness maximized by optimizers. Default is DepthWeightedBandwidthFitness.
+// Genetic, simulated annealing and particle swarm optimizers maximize it directly. Dynamic programming optimizer maximizes the sum
+// of pairwise bandwidths and refines the plan by the fitness via chain-level correction, which is enabled for any fitness other than
+// the default one. MAXBAND and NSGA-II search using their own objectives and use the fitness to report (and for NSGA-II to pick best
+// offsets from the Pareto front).
+func WithFitness(fitness FitnessFunc) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.fitness = fitness
+	}
+}
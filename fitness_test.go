@@ -0,0 +1,52 @@
+package greenwave
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFitnessFuncs(t *testing.T) {
+	junctions := basicTestJuntions()
+	offsets := []float64{0, 78.5, 75.5, 84}
+	corridor := newCorridorWaves(junctionsWithPlan(junctions, offsets, nil), 40, newExtractSettings(), false)
+
+	assert.Equal(t, 4, corridor.JunctionsNum(), "Wrong number of junctions")
+	correctGreenTimes := []float64{50, 45, 28}
+	for i, correctGreenTime := range correctGreenTimes {
+		assert.InDelta(t, correctGreenTime, corridor.GreenTime(i), 1e-9, "Green time of junction %d mismatch", i)
+	}
+
+	assert.InDelta(t, throughWavesFitness(corridor.ThroughWaves, 4), DepthWeightedBandwidthFitness{}.Evaluate(corridor), 1e-9, "Depth weighted bandwidth mismatch")
+	assert.InDelta(t, 18.0, MaxBandFitness{}.Evaluate(corridor), 1e-9, "Max band mismatch")
+	assert.InDelta(t, 1.0, CoverageFitness{}.Evaluate(corridor), 1e-9, "Coverage mismatch")
+	// Segment waves cover 35.5 of 50, 27.5 of 45 and 23 of 28 seconds of upstream green
+	correctStops := (1 - 35.5/50) + (1 - 27.5/45) + (1 - 23.0/28)
+	assert.InDelta(t, -correctStops, StopsFitness{}.Evaluate(corridor), 1e-9, "Stops mismatch")
+
+	weighted := WeightedFitness{{Fitness: MaxBandFitness{}, Weight: 0.5}, {Fitness: StopsFitness{}, Weight: 10}}
+	assert.InDelta(t, 0.5*18-10*correctStops, weighted.Evaluate(corridor), 1e-9, "Weighted fitness mismatch")
+}
+
+func TestWithFitness(t *testing.T) {
+	junctions := basicTestJuntions()
+	offsets := []float64{0, 78.5, 75.5, 84}
+	assert.InDelta(t, 20.8125, EvaluateOffsets(junctions, 40, offsets, nil), 1e-9, "Default fitness should be depth weighted bandwidth")
+	assert.InDelta(t, 18.0, EvaluateOffsets(junctions, 40, offsets, nil, WithFitness(MaxBandFitness{})), 1e-9, "Fitness should be max band")
+
+	// Every optimizer reports the fitness it has been provided with
+	newOptimizers := map[string]Optimizer{
+		"genetic":   NewOptimizerGenetic(junctions, 40, 20, 10, 0.1, 3, CROSSOVER_BLEND, WithFitness(MaxBandFitness{}), WithSeed(42)),
+		"annealing": NewOptimizerAnnealing(junctions, 40, 10, COOLING_GEOMETRIC, 0.9, 10, 10, 0, WithFitness(MaxBandFitness{}), WithSeed(42)),
+		"pso":       NewOptimizerPSO(junctions, 40, 10, 10, 0.7, 1.5, 1.5, SWARM_TOPOLOGY_GLOBAL, WithFitness(MaxBandFitness{}), WithSeed(42)),
+		"nsga2":     NewOptimizerNSGA2(junctions, 40, 20, 10, 0.1, WithFitness(MaxBandFitness{}), WithSeed(42)),
+		"dp":        NewOptimizerDP(junctions, 40, 1.0, false, WithFitness(MaxBandFitness{})),
+		"maxband":   NewOptimizerMaxband(junctions, 40, WithFitness(MaxBandFitness{})),
+	}
+	for name, optimizer := range newOptimizers {
+		bestOffsets := optimizer.Optimize()
+		bestFitness := optimizer.(interface{ BestFitness() float64 }).BestFitness()
+		assert.InDelta(t, EvaluateOffsets(junctions, 40, bestOffsets, nil, WithFitness(MaxBandFitness{})), bestFitness, 1e-9, "Optimizer %s: fitness mismatch", name)
+		assert.Greater(t, bestFitness, 0.0, "Optimizer %s: through band should be found", name)
+	}
+}
@@ -0,0 +1,131 @@
+package greenwave
+
+import "math"
+
+const (
+	// earthRadiusMeters is the mean Earth radius in meters
+	earthRadiusMeters = 6371008.8
+	// wgs84A is the semi-major axis of WGS84 ellipsoid in meters
+	wgs84A = 6378137.0
+	// wgs84F is the flattening of WGS84 ellipsoid
+	wgs84F = 1 / 298.257223563
+	// utmScale is the scale factor on the central meridian of UTM zone
+	utmScale = 0.9996
+	// vincentyMaxIterations is the maximum number of iterations for Vincenty's formula
+	vincentyMaxIterations = 200
+)
+
+func degreesToRadians(deg float64) float64 {
+	return deg * math.Pi / 180.0
+}
+
+// DistanceHaversine returns great-circle distance in meters between two WGS84 points (spherical Earth).
+func DistanceHaversine(p1, p2 Point) float64 {
+	lat1 := degreesToRadians(p1.Y)
+	lat2 := degreesToRadians(p2.Y)
+	dLat := lat2 - lat1
+	dLon := degreesToRadians(p2.X - p1.X)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// DistanceVincenty returns geodesic distance in meters between two WGS84 points on the WGS84 ellipsoid (Vincenty's inverse formula).
+// It falls back to DistanceHaversine for nearly antipodal points when the formula does not converge.
+func DistanceVincenty(p1, p2 Point) float64 {
+	b := (1 - wgs84F) * wgs84A
+	L := degreesToRadians(p2.X - p1.X)
+	U1 := math.Atan((1 - wgs84F) * math.Tan(degreesToRadians(p1.Y)))
+	U2 := math.Atan((1 - wgs84F) * math.Tan(degreesToRadians(p2.Y)))
+	sinU1, cosU1 := math.Sin(U1), math.Cos(U1)
+	sinU2, cosU2 := math.Sin(U2), math.Cos(U2)
+
+	lambda := L
+	var sinSigma, cosSigma, sigma, cosSqAlpha, cos2SigmaM float64
+	converged := false
+	for i := 0; i < vincentyMaxIterations; i++ {
+		sinLambda, cosLambda := math.Sin(lambda), math.Cos(lambda)
+		sinSigma = math.Sqrt(math.Pow(cosU2*sinLambda, 2) + math.Pow(cosU1*sinU2-sinU1*cosU2*cosLambda, 2))
+		if sinSigma == 0 {
+			return 0 // Coincident points
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0.0 // Equatorial line
+		if cosSqAlpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha
+		}
+		C := wgs84F / 16 * cosSqAlpha * (4 + wgs84F*(4-3*cosSqAlpha))
+		lambdaPrev := lambda
+		lambda = L + (1-C)*wgs84F*sinAlpha*(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-lambdaPrev) < 1e-12 {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return DistanceHaversine(p1, p2)
+	}
+	uSq := cosSqAlpha * (wgs84A*wgs84A - b*b) / (b * b)
+	A := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	B := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+	deltaSigma := B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+	return b * A * (sigma - deltaSigma)
+}
+
+// UTMZone returns UTM zone number for the given longitude in degrees.
+func UTMZone(lon float64) int {
+	zone := int(math.Floor((lon+180)/6)) + 1
+	if zone > 60 {
+		zone = 60
+	}
+	if zone < 1 {
+		zone = 1
+	}
+	return zone
+}
+
+// ToUTM projects WGS84 point to the UTM zone defined by the point's longitude.
+// It returns planar point (easting, northing in meters), zone number and hemisphere flag.
+// Points which are not in WGS84 are returned as is with zero zone.
+func (p Point) ToUTM() (projected Point, zone int, north bool) {
+	if p.CRS != CRS_WGS84 {
+		return p, 0, false
+	}
+	zone = UTMZone(p.X)
+	projected = p.ToUTMZone(zone)
+	return projected, zone, p.Y >= 0
+}
+
+// ToUTMZone projects WGS84 point to the given UTM zone (transverse Mercator on WGS84 ellipsoid).
+// It is useful when all junctions of the corridor should be projected into the same zone.
+func (p Point) ToUTMZone(zone int) Point {
+	if p.CRS != CRS_WGS84 {
+		return p
+	}
+	e2 := wgs84F * (2 - wgs84F)
+	ep2 := e2 / (1 - e2)
+	lat := degreesToRadians(p.Y)
+	lon := degreesToRadians(p.X)
+	lon0 := degreesToRadians(float64((zone-1)*6 - 180 + 3))
+
+	sinLat, cosLat, tanLat := math.Sin(lat), math.Cos(lat), math.Tan(lat)
+	N := wgs84A / math.Sqrt(1-e2*sinLat*sinLat)
+	T := tanLat * tanLat
+	C := ep2 * cosLat * cosLat
+	A := cosLat * (lon - lon0)
+	e4 := e2 * e2
+	e6 := e4 * e2
+	M := wgs84A * ((1-e2/4-3*e4/64-5*e6/256)*lat -
+		(3*e2/8+3*e4/32+45*e6/1024)*math.Sin(2*lat) +
+		(15*e4/256+45*e6/1024)*math.Sin(4*lat) -
+		(35*e6/3072)*math.Sin(6*lat))
+
+	easting := utmScale*N*(A+(1-T+C)*math.Pow(A, 3)/6+(5-18*T+T*T+72*C-58*ep2)*math.Pow(A, 5)/120) + 500000.0
+	northing := utmScale * (M + N*tanLat*(A*A/2+(5-T+9*C+4*C*C)*math.Pow(A, 4)/24+(61-58*T+T*T+600*C-330*ep2)*math.Pow(A, 6)/720))
+	if p.Y < 0 {
+		northing += 10000000.0 // False northing for southern hemisphere
+	}
+	return Point{X: easting, Y: northing, CRS: CRS_PLANAR}
+}
@@ -0,0 +1,51 @@
+package greenwave
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeodesicDistance(t *testing.T) {
+	// One degree of latitude at the equator
+	p1 := NewPointWGS84(0, 0)
+	p2 := NewPointWGS84(0, 1)
+	assert.InDelta(t, 110574.389, DistanceVincenty(p1, p2), 0.01, "Vincenty distance mismatch")
+	assert.InDelta(t, 111195.080, DistanceHaversine(p1, p2), 0.01, "Haversine distance mismatch")
+	assert.InDelta(t, DistanceVincenty(p1, p2), p1.DistanceTo(p2), 1e-9, "WGS84 points should use Vincenty distance")
+	assert.Equal(t, 0.0, DistanceVincenty(p1, p1), "Distance between coincident points should be zero")
+
+	// Planar points still use Euclidean distance
+	assert.InDelta(t, 5.0, Point{X: 0, Y: 0}.DistanceTo(Point{X: 3, Y: 4}), 1e-9, "Euclidean distance mismatch")
+
+	// Junctions with geographic locations: ~200 meters along the meridian
+	junctions := []*Junction{
+		NewJunction(basicTestJuntions()[0].Cycle, WithPoint(NewPointWGS84(37.6173, 55.7558))),
+		NewJunction(basicTestJuntions()[1].Cycle, WithPoint(NewPointWGS84(37.6173, 55.757596))),
+	}
+	greenWaves := FindGreenWaves(junctions, 40)
+	for _, wave := range greenWaves[0] {
+		assert.InDelta(t, 200.0, wave.Distance(), 0.5, "Geodesic distance between junctions mismatch")
+	}
+}
+
+func TestToUTM(t *testing.T) {
+	// Equator on the central meridian of zone 31
+	projected, zone, north := NewPointWGS84(3, 0).ToUTM()
+	assert.Equal(t, 31, zone, "Zone mismatch")
+	assert.True(t, north, "Expected northern hemisphere")
+	assert.InDelta(t, 500000.0, projected.X, 1e-6, "Easting mismatch")
+	assert.InDelta(t, 0.0, projected.Y, 1e-6, "Northing mismatch")
+	assert.Equal(t, CRS_PLANAR, projected.CRS, "Projected point should be planar")
+
+	// Eiffel tower
+	projected, zone, _ = NewPointWGS84(2.294516, 48.85826).ToUTM()
+	assert.Equal(t, 31, zone, "Zone mismatch")
+	assert.InDelta(t, 448251.0, projected.X, 10, "Easting mismatch")
+	assert.InDelta(t, 5411932.0, projected.Y, 10, "Northing mismatch")
+
+	// Southern hemisphere uses false northing
+	projected, _, north = NewPointWGS84(3, -1).ToUTM()
+	assert.False(t, north, "Expected southern hemisphere")
+	assert.InDelta(t, 10000000.0-110579.0, projected.Y, 50, "Northing mismatch")
+}
@@ -14,6 +14,8 @@ type GreenInterval struct {
 	Start float64
 	// End time of the green interval in seconds
 	End float64
+	// Whether the interval belongs to the declared coordinated movement of the junction (see Movement)
+	movement bool
 }
 
 // NewGreenInterval creates a new GreenInterval instance with the specified phase index, start time, and end time.
@@ -30,7 +32,26 @@ func (interval *GreenInterval) CanConnect(otherInterval *GreenInterval) *GreenIn
 	overlapStart := math.Max(interval.Start, otherInterval.Start)
 	overlapEnd := math.Min(interval.End, otherInterval.End)
 	if overlapEnd-overlapStart > eps {
-		return NewGreenInterval(interval.PhaseIdx, overlapStart, overlapEnd)
+		return interval.withBounds(overlapStart, overlapEnd)
 	}
 	return nil
 }
+
+// withBounds returns a copy of the interval with the specified start and end times.
+func (interval *GreenInterval) withBounds(start, end float64) *GreenInterval {
+	return &GreenInterval{
+		PhaseIdx: interval.PhaseIdx,
+		Start:    start,
+		End:      end,
+		movement: interval.movement,
+	}
+}
+
+// SameMovement checks if two intervals of the same junction serve the same movement, so waves could be connected through them.
+// Intervals of the declared coordinated movement connect regardless of their phase indices, otherwise phase indices must match.
+func (interval *GreenInterval) SameMovement(otherInterval *GreenInterval) bool {
+	if interval.movement && otherInterval.movement {
+		return true
+	}
+	return interval.PhaseIdx == otherInterval.PhaseIdx
+}
@@ -3,6 +3,7 @@ package greenwave
 import (
 	"testing"
 
+	"github.com/LdDl/greenwave/color"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -90,3 +91,36 @@ func TestCanConnect(t *testing.T) {
 	assert.InDelta(t, 39.5, connectedInterval.Start, 0.01, "Expected start time to be 39.5")
 	assert.InDelta(t, 48, connectedInterval.End, 0.01, "Expected end time to be 48")
 }
+
+func TestGetGreenIntervalsEffectiveGreen(t *testing.T) {
+	cycle := []*Phase{
+		NewPhase(0, []*Signal{
+			NewSignal(2, color.REDYELLOW),
+			NewSignal(20, color.GREEN),
+			NewSignal(3, color.YELLOW),
+			NewSignal(10, color.RED),
+		}),
+		NewPhase(1, []*Signal{
+			NewSignal(10, color.GREEN),
+			NewSignal(5, color.GREENRIGHT),
+			NewSignal(3, color.YELLOW),
+			NewSignal(7, color.RED),
+		}),
+	}
+
+	// Without policy only GREEN signals are considered
+	junction := NewJunction(cycle)
+	correctGreenIntervals := []*GreenInterval{NewGreenInterval(0, 2, 22), NewGreenInterval(1, 35, 45)}
+	assert.Equal(t, correctGreenIntervals, junction.GetGreenIntervals(), "Mismatch in raw green intervals")
+
+	// Start-up lost time, yellow extension, REDYELLOW and GREENRIGHT usage
+	policy := NewEffectiveGreen(2, 2, WithRedYellowUsage(), WithGreenRightUsage())
+	junction = NewJunction(cycle, WithEffectiveGreen(policy))
+	correctGreenIntervals = []*GreenInterval{NewGreenInterval(0, 2, 24), NewGreenInterval(1, 37, 52)}
+	assert.Equal(t, correctGreenIntervals, junction.GetGreenIntervals(), "Mismatch in effective green intervals")
+
+	// Corridor policy applies only to junctions without own policy
+	corridorPolicy := NewEffectiveGreen(3, 0)
+	assert.Equal(t, []*GreenInterval{NewGreenInterval(0, 5, 22), NewGreenInterval(1, 38, 45)}, NewJunction(cycle).greenIntervals(corridorPolicy, ""), "Mismatch in corridor effective green intervals")
+	assert.Equal(t, correctGreenIntervals, junction.greenIntervals(corridorPolicy, ""), "Junction policy should take precedence over corridor one")
+}
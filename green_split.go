@@ -0,0 +1,268 @@
+package greenwave
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// GreenSplitMode defines how signal durations are adjusted during green split optimization
+type GreenSplitMode uint8
+
+const (
+	// GREEN_SPLIT_FIXED_CYCLE rebalances signal durations within their bounds while the cycle length of each junction stays fixed
+	GREEN_SPLIT_FIXED_CYCLE GreenSplitMode = iota
+	// GREEN_SPLIT_FREE_CYCLE changes signal durations within their bounds, so the cycle length could change too.
+	// Junctions keep a common cycle length (or its multiple for junctions with originally longer cycles, e.g. double cycling),
+	// so the corridor hyperperiod does not grow. If bounds do not allow any common cycle then each junction keeps its own cycle length
+	GREEN_SPLIT_FREE_CYCLE
+)
+
+var greenSplitModeToStr = [...]string{"fixed_cycle", "free_cycle"}
+
+// String returns the string representation of the GreenSplitMode
+func (mode GreenSplitMode) String() string {
+	return greenSplitModeToStr[mode]
+}
+
+// bounds returns the duration bounds of the signal. Inconsistent bounds are extended to contain the current duration.
+func (s *Signal) bounds() (float64, float64) {
+	return math.Min(s.MinDuration, s.Duration), math.Max(s.MaxDuration, s.Duration)
+}
+
+// greenSplit contains duration bounds of every phase signal of the junction (in cycle order).
+type greenSplit struct {
+	mode GreenSplitMode
+	// minDurations contains lower bounds of signal durations
+	minDurations []float64
+	// maxDurations contains upper bounds of signal durations
+	maxDurations []float64
+	// cycle is the original cycle length of the junction
+	cycle float64
+	// multiple is the ratio of the junction cycle to the common cycle in free cycle mode
+	multiple float64
+}
+
+// newGreenSplit collects duration bounds of the junction signals.
+func newGreenSplit(junction *Junction, mode GreenSplitMode) *greenSplit {
+	split := &greenSplit{
+		mode:     mode,
+		cycle:    junction.totalDuration,
+		multiple: 1,
+	}
+	for _, phase := range junction.Cycle {
+		for _, signal := range phase.Signals {
+			minDuration, maxDuration := signal.bounds()
+			split.minDurations = append(split.minDurations, minDuration)
+			split.maxDurations = append(split.maxDurations, maxDuration)
+		}
+	}
+	return split
+}
+
+// newGreenSplits collects duration bounds of signals of every junction.
+// In free cycle mode junctions are bound to the common cycle (see GREEN_SPLIT_FREE_CYCLE): the multiple of each junction
+// is its original cycle length divided by the shortest one and rounded. If there is no common cycle which satisfies bounds of every junction
+// then fixed cycle mode is used instead.
+func newGreenSplits(junctions []*Junction, mode GreenSplitMode) []*greenSplit {
+	splits := make([]*greenSplit, len(junctions))
+	for i, junction := range junctions {
+		splits[i] = newGreenSplit(junction, mode)
+	}
+	if mode != GREEN_SPLIT_FREE_CYCLE || len(splits) == 0 {
+		return splits
+	}
+	shortest := math.Inf(1)
+	for _, split := range splits {
+		if split.cycle > 0 {
+			shortest = math.Min(shortest, split.cycle)
+		}
+	}
+	if !math.IsInf(shortest, 1) {
+		for _, split := range splits {
+			split.multiple = math.Max(1, math.Round(split.cycle/shortest))
+		}
+	}
+	if _, _, ok := commonCycleBounds(splits); !ok {
+		for _, split := range splits {
+			split.mode = GREEN_SPLIT_FIXED_CYCLE
+		}
+	}
+	return splits
+}
+
+// commonCycleBounds returns the range of the common cycle length (aligned to the hyperperiod resolution)
+// which satisfies duration bounds of every junction. Returns false if there is no such cycle length
+func commonCycleBounds(splits []*greenSplit) (float64, float64, bool) {
+	lower, upper := 0.0, math.Inf(1)
+	for _, split := range splits {
+		lower = math.Max(lower, split.minCycle()/split.multiple)
+		upper = math.Min(upper, split.maxCycle()/split.multiple)
+	}
+	lower = math.Ceil(lower*hyperperiodTicksPerSecond-1e-9) / hyperperiodTicksPerSecond
+	upper = math.Floor(upper*hyperperiodTicksPerSecond+1e-9) / hyperperiodTicksPerSecond
+	return lower, upper, lower > 0 && lower <= upper
+}
+
+// alignCycles adjusts durations of every junction in free cycle mode, so their cycle lengths are multiples of the common cycle.
+// The common cycle is the average of current ones (divided by multiples) aligned to the hyperperiod resolution and clamped to feasible range.
+// Durations are modified in place. Does nothing if splits are not in free cycle mode
+func alignCycles(splits []*greenSplit, durations [][]float64) {
+	lower, upper, ok := commonCycleBounds(splits)
+	if !ok || splits[0].mode != GREEN_SPLIT_FREE_CYCLE {
+		return
+	}
+	common := 0.0
+	for i, split := range splits {
+		for _, duration := range durations[i] {
+			common += duration / split.multiple
+		}
+	}
+	common = math.Round(common/float64(len(splits))*hyperperiodTicksPerSecond) / hyperperiodTicksPerSecond
+	common = math.Max(lower, math.Min(upper, common))
+	for i, split := range splits {
+		split.fit(durations[i], common*split.multiple)
+	}
+}
+
+// minCycle returns the shortest possible cycle length of the junction.
+func (split *greenSplit) minCycle() float64 {
+	if split.mode == GREEN_SPLIT_FIXED_CYCLE {
+		return split.cycle
+	}
+	total := 0.0
+	for _, minDuration := range split.minDurations {
+		total += minDuration
+	}
+	return total
+}
+
+// maxCycle returns the longest possible cycle length of the junction.
+func (split *greenSplit) maxCycle() float64 {
+	if split.mode == GREEN_SPLIT_FIXED_CYCLE {
+		return split.cycle
+	}
+	total := 0.0
+	for _, maxDuration := range split.maxDurations {
+		total += maxDuration
+	}
+	return total
+}
+
+// random returns random feasible signal durations.
+func (split *greenSplit) random(random *rand.Rand) []float64 {
+	durations := make([]float64, len(split.minDurations))
+	for i := range durations {
+		durations[i] = randomFloat(random, split.minDurations[i], split.maxDurations[i])
+	}
+	split.repair(durations)
+	return durations
+}
+
+// repair clamps durations to their bounds and (in fixed cycle mode) redistributes the difference from the cycle length
+// between signals proportionally to their slack. Durations are modified in place.
+func (split *greenSplit) repair(durations []float64) {
+	if split.mode == GREEN_SPLIT_FIXED_CYCLE {
+		split.fit(durations, split.cycle)
+		return
+	}
+	for i := range durations {
+		durations[i] = math.Max(split.minDurations[i], math.Min(split.maxDurations[i], durations[i]))
+	}
+}
+
+// fit clamps durations to their bounds and redistributes the difference from the given cycle length
+// between signals proportionally to their slack. Durations are modified in place.
+func (split *greenSplit) fit(durations []float64, cycle float64) {
+	total := 0.0
+	for i := range durations {
+		durations[i] = math.Max(split.minDurations[i], math.Min(split.maxDurations[i], durations[i]))
+		total += durations[i]
+	}
+	diff := cycle - total
+	if math.Abs(diff) < 1e-9 {
+		return
+	}
+	slack := 0.0
+	for i := range durations {
+		if diff > 0 {
+			slack += split.maxDurations[i] - durations[i]
+		} else {
+			slack += durations[i] - split.minDurations[i]
+		}
+	}
+	if slack <= 0 {
+		return // Nothing could be adjusted
+	}
+	share := math.Min(1, math.Abs(diff)/slack)
+	for i := range durations {
+		if diff > 0 {
+			durations[i] += share * (split.maxDurations[i] - durations[i])
+		} else {
+			durations[i] -= share * (durations[i] - split.minDurations[i])
+		}
+	}
+}
+
+// round rounds durations to the given resolution in seconds. The residual difference from the cycle length (the original one in fixed cycle mode
+// and the one of given durations in free cycle mode) is given to the first signal which could absorb it within its bounds. Returns new slice.
+func (split *greenSplit) round(durations []float64, resolution float64) []float64 {
+	cycle := split.cycle
+	if split.mode != GREEN_SPLIT_FIXED_CYCLE {
+		cycle = 0
+		for _, duration := range durations {
+			cycle += duration
+		}
+	}
+	rounded := make([]float64, len(durations))
+	for i, duration := range durations {
+		rounded[i] = roundToResolution(duration, resolution)
+	}
+	total := 0.0
+	for _, duration := range rounded {
+		total += duration
+	}
+	diff := cycle - total
+	if math.Abs(diff) < 1e-9 {
+		return rounded
+	}
+	for i := range rounded {
+		adjusted := rounded[i] + diff
+		if adjusted >= split.minDurations[i]-1e-9 && adjusted <= split.maxDurations[i]+1e-9 {
+			rounded[i] = math.Round(adjusted*1e9) / 1e9
+			break
+		}
+	}
+	return rounded
+}
+
+// GetSignalDurations returns durations of all phase signals of the junction in cycle order.
+func (jun *Junction) GetSignalDurations() []float64 {
+	durations := make([]float64, 0)
+	for _, phase := range jun.Cycle {
+		for _, signal := range phase.Signals {
+			durations = append(durations, signal.Duration)
+		}
+	}
+	return durations
+}
+
+// SetSignalDurations sets durations of phase signals of the junction in cycle order (see GetSignalDurations)
+// and recalculates durations of phases and the cycle. Extra values are ignored, missing ones keep signals as is.
+// Note: signal groups timelines are not adjusted.
+func (jun *Junction) SetSignalDurations(durations []float64) {
+	idx := 0
+	totalDuration := 0.0
+	for _, phase := range jun.Cycle {
+		phaseSeconds := 0.0
+		for _, signal := range phase.Signals {
+			if idx < len(durations) {
+				signal.Duration = durations[idx]
+			}
+			idx++
+			phaseSeconds += signal.Duration
+		}
+		phase.totalSeconds = phaseSeconds
+		totalDuration += phaseSeconds
+	}
+	jun.totalDuration = totalDuration
+}
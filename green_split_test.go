@@ -0,0 +1,153 @@
+package greenwave
+
+import (
+	"testing"
+
+	"github.com/LdDl/greenwave/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func greenSplitTestJunctions() []*Junction {
+	junctions := basicTestJuntions()
+	for _, junction := range junctions {
+		for _, phase := range junction.Cycle {
+			for _, signal := range phase.Signals {
+				if signal.Color == color.GREEN {
+					WithMinDuration(signal.Duration - 5)(signal)
+					WithMaxDuration(signal.Duration + 5)(signal)
+				}
+			}
+		}
+	}
+	return junctions
+}
+
+func TestGreenSplitRepair(t *testing.T) {
+	junction := NewJunction([]*Phase{
+		NewPhase(0, []*Signal{
+			NewSignal(30, color.GREEN, WithMinDuration(20), WithMaxDuration(40)),
+			NewSignal(30, color.RED),
+		}),
+		NewPhase(1, []*Signal{
+			NewSignal(20, color.GREEN, WithMinDuration(10), WithMaxDuration(30)),
+			NewSignal(20, color.RED),
+		}),
+	})
+	split := newGreenSplit(junction, GREEN_SPLIT_FIXED_CYCLE)
+	durations := []float64{40, 30, 30, 20}
+	split.repair(durations)
+	assert.InDeltaSlice(t, []float64{30, 30, 20, 20}, durations, 1e-9, "Difference from the cycle should be distributed proportionally to slack")
+
+	durations = []float64{20, 30, 0, 20}
+	split.repair(durations)
+	assert.InDeltaSlice(t, []float64{30, 30, 20, 20}, durations, 1e-9, "Durations should be clamped to bounds before redistribution")
+
+	split = newGreenSplit(junction, GREEN_SPLIT_FREE_CYCLE)
+	durations = []float64{40, 30, 30, 20}
+	split.repair(durations)
+	assert.InDeltaSlice(t, []float64{40, 30, 30, 20}, durations, 1e-9, "Free cycle should keep feasible durations as is")
+	assert.InDelta(t, 120.0, split.maxCycle(), 1e-9, "Max cycle mismatch")
+
+	junction.SetSignalDurations([]float64{35, 30, 15, 20})
+	assert.InDelta(t, 100.0, junction.GetTotalDuration(), 1e-9, "Cycle duration should be recalculated")
+	assert.InDelta(t, 35.0, junction.Cycle[1].GetTotalSeconds(), 1e-9, "Phase duration should be recalculated")
+}
+
+func TestOptimizerGeneticGreenSplits(t *testing.T) {
+	junctions := greenSplitTestJunctions()
+	optimizer := NewOptimizerGenetic(junctions, 40, 20, 10, 0.1, 3, CROSSOVER_BLEND, WithGreenSplits(GREEN_SPLIT_FIXED_CYCLE), WithOutputResolution(0.1)).(*OptimizerGenetic)
+	optimizer.Optimize()
+	bestDurations := optimizer.BestDurations()
+	assert.Equal(t, len(junctions), len(bestDurations), "Expected durations for each junction")
+	for i, junction := range junctions {
+		split := newGreenSplit(junction, GREEN_SPLIT_FIXED_CYCLE)
+		total := 0.0
+		for j, duration := range bestDurations[i] {
+			assert.GreaterOrEqual(t, duration, split.minDurations[j]-1e-9, "Duration is below its bound")
+			assert.LessOrEqual(t, duration, split.maxDurations[j]+1e-9, "Duration is above its bound")
+			total += duration
+		}
+		assert.InDelta(t, 85.0, total, 1e-9, "Cycle length should be kept for junction %d", i)
+	}
+}
+
+func TestOptimizerGeneticFreeCycleOffsets(t *testing.T) {
+	junctions := greenSplitTestJunctions()
+	for _, junction := range junctions {
+		for _, phase := range junction.Cycle {
+			for _, signal := range phase.Signals {
+				if signal.Color == color.GREEN {
+					WithMaxDuration(signal.Duration + 60)(signal) // Longest possible cycle is far from typical ones
+				}
+			}
+		}
+	}
+	for seed := uint64(1); seed <= 10; seed++ {
+		optimizer := NewOptimizerGenetic(junctions, 40, 20, 10, 0.5, 3, CROSSOVER_BLEND, WithGreenSplits(GREEN_SPLIT_FREE_CYCLE), WithOutputResolution(0.1), WithSeed(seed)).(*OptimizerGenetic)
+		bestOffsets := optimizer.Optimize()
+		bestDurations := optimizer.BestDurations()
+		for i := range junctions {
+			cycle := 0.0
+			for _, duration := range bestDurations[i] {
+				cycle += duration
+			}
+			assert.GreaterOrEqual(t, bestOffsets[i], 0.0, "Offset of junction %d is negative (seed %d)", i, seed)
+			assert.Less(t, bestOffsets[i], cycle, "Offset of junction %d should be within its resulting cycle (seed %d)", i, seed)
+		}
+	}
+}
+
+func TestOptimizerGeneticFreeCycleCommon(t *testing.T) {
+	newJunction := func(green, red, minGreen, maxGreen float64, x float64) *Junction {
+		return NewJunction([]*Phase{
+			NewPhase(0, []*Signal{
+				NewSignal(green, color.GREEN, WithMinDuration(minGreen), WithMaxDuration(maxGreen)),
+				NewSignal(red, color.RED),
+			}),
+		}, WithPoint(Point{X: x, Y: 0}))
+	}
+	// Free cycles are within [50; 80] and [65; 90] seconds: the common one is within [65; 80]
+	junctions := []*Junction{
+		newJunction(30, 30, 20, 50, 0),
+		newJunction(30, 40, 25, 50, 200),
+	}
+	for seed := uint64(1); seed <= 5; seed++ {
+		optimizer := NewOptimizerGenetic(junctions, 40, 20, 10, 0.5, 3, CROSSOVER_BLEND, WithGreenSplits(GREEN_SPLIT_FREE_CYCLE), WithOutputResolution(0.1), WithSeed(seed)).(*OptimizerGenetic)
+		bestOffsets := optimizer.Optimize()
+		bestDurations := optimizer.BestDurations()
+		cycles := make([]float64, len(junctions))
+		planned := junctionsWithPlan(junctions, bestOffsets, bestDurations)
+		for i := range junctions {
+			for _, duration := range bestDurations[i] {
+				cycles[i] += duration
+			}
+		}
+		assert.InDelta(t, cycles[0], cycles[1], 1e-9, "Junctions should share the common cycle (seed %d)", seed)
+		assert.GreaterOrEqual(t, cycles[0], 65.0-1e-9, "Common cycle is below the feasible range (seed %d)", seed)
+		assert.LessOrEqual(t, cycles[0], 80.0+1e-9, "Common cycle is above the feasible range (seed %d)", seed)
+		hyperperiod, ok := CorridorHyperperiod(planned)
+		assert.True(t, ok, "Hyperperiod should be within the limit (seed %d)", seed)
+		assert.InDelta(t, cycles[0], hyperperiod, 1e-9, "Hyperperiod should be the common cycle (seed %d)", seed)
+	}
+
+	// Double cycling: the second junction keeps twice the cycle of the first one
+	junctions = []*Junction{
+		newJunction(30, 30, 20, 50, 0),
+		newJunction(60, 60, 40, 80, 200),
+	}
+	optimizer := NewOptimizerGenetic(junctions, 40, 20, 10, 0.5, 3, CROSSOVER_BLEND, WithGreenSplits(GREEN_SPLIT_FREE_CYCLE), WithSeed(1)).(*OptimizerGenetic)
+	optimizer.Optimize()
+	bestDurations := optimizer.BestDurations()
+	assert.InDelta(t, 2*(bestDurations[0][0]+bestDurations[0][1]), bestDurations[1][0]+bestDurations[1][1], 1e-9, "Double cycle should be kept")
+
+	// Bounds without common cycle: each junction keeps its own cycle length
+	junctions = []*Junction{
+		newJunction(30, 30, 25, 35, 0),
+		newJunction(30, 45, 25, 35, 200),
+	}
+	optimizer = NewOptimizerGenetic(junctions, 40, 20, 10, 0.5, 3, CROSSOVER_BLEND, WithGreenSplits(GREEN_SPLIT_FREE_CYCLE), WithSeed(1)).(*OptimizerGenetic)
+	optimizer.Optimize()
+	bestDurations = optimizer.BestDurations()
+	assert.InDelta(t, 60.0, bestDurations[0][0]+bestDurations[0][1], 1e-9, "Cycle of the first junction should be kept")
+	assert.InDelta(t, 75.0, bestDurations[1][0]+bestDurations[1][1], 1e-9, "Cycle of the second junction should be kept")
+}
@@ -14,13 +14,29 @@ type GreenWave struct {
 	travelTime float64
 	// Bandwidth of the green wave in seconds.
 	bandwidth float64
+	// Road polyline between the two junctions in travel direction (optional)
+	geometry []Point
+	// Data for speed-robust bandwidth evaluation. Presented only if speed range is enabled
+	tolerance *speedTolerance
+}
+
+// speedTolerance contains data which is required to evaluate speed-robust bandwidth of the green wave
+type speedTolerance struct {
+	// Full green window on the first junction which contains the wave
+	greenJunOne *GreenInterval
+	// Full green window on the second junction which contains the wave
+	greenJunTwo *GreenInterval
+	// Travel time in seconds for the maximum speed
+	minTravelTime float64
+	// Travel time in seconds for the minimum speed
+	maxTravelTime float64
 }
 
 // NewGreenWave creates a new GreenWave instance with the specified parameters.
 func NewGreenWave(intervalJunOne, intervalJunTwo *GreenInterval, distanceMeters, travelTimeSeconds float64) *GreenWave {
 	return &GreenWave{
-		intervalJunOne: NewGreenInterval(intervalJunOne.PhaseIdx, intervalJunOne.Start, intervalJunOne.End),
-		intervalJunTwo: NewGreenInterval(intervalJunTwo.PhaseIdx, intervalJunTwo.Start, intervalJunTwo.End),
+		intervalJunOne: intervalJunOne.withBounds(intervalJunOne.Start, intervalJunOne.End),
+		intervalJunTwo: intervalJunTwo.withBounds(intervalJunTwo.Start, intervalJunTwo.End),
 		distance:       distanceMeters,
 		travelTime:     travelTimeSeconds,
 		bandwidth:      float64(intervalJunOne.End - intervalJunOne.Start),
@@ -30,11 +46,13 @@ func NewGreenWave(intervalJunOne, intervalJunTwo *GreenInterval, distanceMeters,
 // Clone creates a deep copy of the GreenWave instance.
 func (gw *GreenWave) Clone() *GreenWave {
 	return &GreenWave{
-		intervalJunOne: NewGreenInterval(gw.intervalJunOne.PhaseIdx, gw.intervalJunOne.Start, gw.intervalJunOne.End),
-		intervalJunTwo: NewGreenInterval(gw.intervalJunTwo.PhaseIdx, gw.intervalJunTwo.Start, gw.intervalJunTwo.End),
+		intervalJunOne: gw.intervalJunOne.withBounds(gw.intervalJunOne.Start, gw.intervalJunOne.End),
+		intervalJunTwo: gw.intervalJunTwo.withBounds(gw.intervalJunTwo.Start, gw.intervalJunTwo.End),
 		distance:       gw.distance,
 		travelTime:     gw.travelTime,
 		bandwidth:      gw.bandwidth,
+		geometry:       gw.geometry,
+		tolerance:      gw.tolerance,
 	}
 }
 
@@ -63,6 +81,12 @@ func (gw *GreenWave) Bandwidth() float64 {
 	return gw.bandwidth
 }
 
+// Geometry returns the road polyline between the two junctions in travel direction.
+// Could be nil if geometry has not been provided. Returns slice, do not modify it
+func (gw *GreenWave) Geometry() []Point {
+	return gw.geometry
+}
+
 // FindGreenWavesBetweenIntervals finds green waves between two sets of green intervals.
 func FindGreenWavesBetweenIntervals(greenIntervalsOne, greenIntervalsTwo []*GreenInterval, distanceMeters, travelTimeSeconds float64) []*GreenWave {
 	var greenWaves []*GreenWave
@@ -85,8 +109,8 @@ func FindGreenWavesBetweenIntervals(greenIntervalsOne, greenIntervalsTwo []*Gree
 			// adjustedStartJunOne < adjustedEndJunOne - ensure valid interval
 			if adjustedStartJunOne >= startOne && adjustedEndJunOne <= endOne && adjustedStartJunOne < adjustedEndJunOne {
 				greenWave := NewGreenWave(
-					NewGreenInterval(greenIntervalOne.PhaseIdx, adjustedStartJunOne, adjustedEndJunOne),
-					NewGreenInterval(greenIntervalTwo.PhaseIdx, overlapStart, overlapEnd),
+					greenIntervalOne.withBounds(adjustedStartJunOne, adjustedEndJunOne),
+					greenIntervalTwo.withBounds(overlapStart, overlapEnd),
 					distanceMeters,
 					travelTimeSeconds,
 				)
@@ -97,53 +121,120 @@ func FindGreenWavesBetweenIntervals(greenIntervalsOne, greenIntervalsTwo []*Gree
 	return greenWaves
 }
 
+// adjustIntervalsByOffset shifts green intervals of the junction by its offset and unrolls them across the hyperperiod.
+// Intervals wrapped by the hyperperiod end are split. If hyperperiod is not greater than the junction cycle then the cycle itself is used.
+// Fallback effective green policy is used if junction has no own policy. Non-empty signal group name selects the group timeline.
+func adjustIntervalsByOffset(junction *Junction, fallback *EffectiveGreen, signalGroup string, hyperperiod float64) []*GreenInterval {
+	greenIntervals := junction.greenIntervals(fallback, signalGroup)
+	offset := junction.GetOffset()
+	cycleDuration := junction.totalDuration
+	if hyperperiod < cycleDuration {
+		hyperperiod = cycleDuration
+	}
+	repeats := int(math.Round(hyperperiod / cycleDuration))
+	adjustedIntervals := make([]*GreenInterval, 0, len(greenIntervals)*repeats)
+	for _, interval := range greenIntervals {
+		start := wrapTime(interval.Start+offset, cycleDuration)
+		length := interval.End - interval.Start
+		for k := 0; k < repeats; k++ {
+			repeatStart := start + float64(k)*cycleDuration
+			repeatEnd := repeatStart + length
+			if repeatEnd > hyperperiod {
+				// Interval split due hyperperiod wrap
+				adjustedIntervals = append(adjustedIntervals, interval.withBounds(repeatStart, hyperperiod))
+				adjustedIntervals = append(adjustedIntervals, interval.withBounds(0, repeatEnd-hyperperiod))
+			} else {
+				// Common case
+				adjustedIntervals = append(adjustedIntervals, interval.withBounds(repeatStart, repeatEnd))
+			}
+		}
+	}
+	return adjustedIntervals
+}
+
 // FindGreenWaves finds green waves between a sequence of junctions based on their green intervals and desired speed.
 // It returns a slice of slices, where each inner slice contains green waves for the segment between two junctions.
-func FindGreenWaves(junctions []*Junction, desiredSpeedKmh float64) [][]*GreenWave {
-	speedMs := desiredSpeedKmh / 3.6
+// Junctions may have different cycle lengths (e.g. half/double cycling): green intervals are unrolled across the corridor hyperperiod (see Hyperperiod),
+// so waves are reported within a single hyperperiod.
+// Optional settings (e.g. WithSegments) could be provided via options.
+func FindGreenWaves(junctions []*Junction, desiredSpeedKmh float64, options ...func(*ExtractSettings)) [][]*GreenWave {
+	return findGreenWaves(junctions, desiredSpeedKmh, newExtractSettings(options...), false)
+}
+
+// FindGreenWavesInbound finds green waves in the inbound direction, i.e. from the last junction towards the first one.
+// Segments are returned in travel order: the first segment is the one between the last and the penultimate junctions.
+func FindGreenWavesInbound(junctions []*Junction, desiredSpeedKmh float64, options ...func(*ExtractSettings)) [][]*GreenWave {
+	return findGreenWaves(reverseJunctions(junctions), desiredSpeedKmh, newExtractSettings(options...), true)
+}
+
+// FindTwoWayGreenWaves finds green waves in both directions of the corridor.
+// Outbound waves go from the first junction to the last one, inbound waves go backwards (see FindGreenWavesInbound).
+func FindTwoWayGreenWaves(junctions []*Junction, outboundSpeedKmh, inboundSpeedKmh float64, options ...func(*ExtractSettings)) (outbound [][]*GreenWave, inbound [][]*GreenWave) {
+	outbound = FindGreenWaves(junctions, outboundSpeedKmh, options...)
+	inbound = FindGreenWavesInbound(junctions, inboundSpeedKmh, options...)
+	return outbound, inbound
+}
+
+// findGreenWaves finds green waves between junctions given in travel order.
+// If reversed is set then junctions are in inbound order and segments are looked up backwards.
+func findGreenWaves(junctions []*Junction, desiredSpeedKmh float64, settings *ExtractSettings, reversed bool) [][]*GreenWave {
+	if len(junctions) < 2 {
+		return [][]*GreenWave{}
+	}
+	hyperperiod := settings.hyperperiod(junctions)
 	waves := make([][]*GreenWave, 0, len(junctions)-1)
 	for i := 0; i < len(junctions)-1; i++ {
 		junctionOne := junctions[i]
 		junctionTwo := junctions[i+1]
-		greenIntervalsOne := junctionOne.GetGreenIntervals()
-		greenIntervalsTwo := junctionTwo.GetGreenIntervals()
-
-		offsetJunctionOne := junctionOne.GetOffset()
-		offsetJunctionTwo := junctionTwo.GetOffset()
-
-		adjustedIntervalsOne := make([]*GreenInterval, 0, len(greenIntervalsOne))
-		for _, interval := range greenIntervalsOne {
-			start := (int(interval.Start) + offsetJunctionOne) % junctionOne.totalDuration
-			end := (int(interval.End) + offsetJunctionOne) % junctionOne.totalDuration
-			if end < start {
-				// Interval split due cycle wrap
-				adjustedIntervalsOne = append(adjustedIntervalsOne, NewGreenInterval(interval.PhaseIdx, float64(start), float64(junctionOne.totalDuration)))
-				adjustedIntervalsOne = append(adjustedIntervalsOne, NewGreenInterval(interval.PhaseIdx, 0, float64(end)))
-			} else {
-				// Common case
-				adjustedIntervalsOne = append(adjustedIntervalsOne, NewGreenInterval(interval.PhaseIdx, float64(start), float64(end)))
-			}
-		}
 
-		adjustedIntervalsTwo := make([]*GreenInterval, 0, len(greenIntervalsTwo))
-		for _, interval := range greenIntervalsTwo {
-			start := (int(interval.Start) + offsetJunctionTwo) % junctionTwo.totalDuration
-			end := (int(interval.End) + offsetJunctionTwo) % junctionTwo.totalDuration
-			if end < start {
-				// Interval split due cycle wrap
-				adjustedIntervalsTwo = append(adjustedIntervalsTwo, NewGreenInterval(interval.PhaseIdx, float64(start), float64(junctionTwo.totalDuration)))
-				adjustedIntervalsTwo = append(adjustedIntervalsTwo, NewGreenInterval(interval.PhaseIdx, 0, float64(end)))
-			} else {
-				// Common case
-				adjustedIntervalsTwo = append(adjustedIntervalsTwo, NewGreenInterval(interval.PhaseIdx, float64(start), float64(end)))
-			}
+		// Indices of junctions and segment in outbound order
+		junctionOneIdx, junctionTwoIdx, segmentIdx := i, i+1, i
+		if reversed {
+			junctionOneIdx, junctionTwoIdx, segmentIdx = len(junctions)-1-i, len(junctions)-2-i, len(junctions)-2-i
 		}
+		adjustedIntervalsOne := adjustIntervalsByOffset(junctionOne, settings.effectiveGreen, settings.signalGroup(junctionOneIdx, reversed), hyperperiod)
+		adjustedIntervalsTwo := adjustIntervalsByOffset(junctionTwo, settings.effectiveGreen, settings.signalGroup(junctionTwoIdx, reversed), hyperperiod)
 
-		distanceMeters := math.Sqrt(math.Pow(junctionOne.point.X-junctionTwo.point.X, 2) + math.Pow(junctionOne.point.Y-junctionTwo.point.Y, 2))
-		travelTimeSeconds := distanceMeters / speedMs
+		segment := settings.segment(segmentIdx)
+		distanceMeters := segment.Distance(junctionOne, junctionTwo)
+		travelTimeSeconds := segment.TravelTime(distanceMeters, desiredSpeedKmh)
 
 		segmentWaves := FindGreenWavesBetweenIntervals(adjustedIntervalsOne, adjustedIntervalsTwo, distanceMeters, travelTimeSeconds)
+		geometry := segment.GeometryInDirection(reversed)
+		for _, wave := range segmentWaves {
+			wave.geometry = geometry
+		}
+		if settings.speedRange {
+			minTravelTime, maxTravelTime := segment.TravelTimeRange(distanceMeters, settings.minSpeedKmh, settings.maxSpeedKmh)
+			for _, wave := range segmentWaves {
+				wave.tolerance = &speedTolerance{
+					greenJunOne:   containingInterval(adjustedIntervalsOne, wave.intervalJunOne),
+					greenJunTwo:   containingInterval(adjustedIntervalsTwo, wave.intervalJunTwo),
+					minTravelTime: minTravelTime,
+					maxTravelTime: maxTravelTime,
+				}
+			}
+		}
 		waves = append(waves, segmentWaves)
 	}
 	return waves
 }
+
+// containingInterval returns the interval which contains the given one. Fallbacks to the given interval itself if nothing found.
+func containingInterval(intervals []*GreenInterval, interval *GreenInterval) *GreenInterval {
+	for _, candidate := range intervals {
+		if candidate.Start <= interval.Start+eps && interval.End <= candidate.End+eps {
+			return candidate
+		}
+	}
+	return interval
+}
+
+// reverseJunctions returns a new slice with junctions in reversed (inbound travel) order.
+func reverseJunctions(junctions []*Junction) []*Junction {
+	reversed := make([]*Junction, len(junctions))
+	for i, junction := range junctions {
+		reversed[len(junctions)-1-i] = junction
+	}
+	return reversed
+}
@@ -1,5 +1,7 @@
 package greenwave
 
+import "math"
+
 // GreenWaveChain represents a chain of green waves. Wraps a slice of GreenWave basically
 type GreenWaveChain struct {
 	greenWaves []*GreenWave
@@ -23,8 +25,8 @@ func FindWaveConnections(segmentsWaves [][]*GreenWave) map[WaveID][]WaveID {
 			waveFromID := WaveID{SegmentIdx: segIdx, WaveIdx: waveFromIdx}
 			connections[waveFromID] = []WaveID{}
 			for waveToIdx, waveTo := range nextSegment {
-				// Waves can only connect if they are in the same phase (@todo: consider phase transitions)
-				if waveFrom.intervalJunTwo.PhaseIdx != waveTo.intervalJunOne.PhaseIdx {
+				// Waves can only connect if they are in the same phase or serve the same coordinated movement
+				if !waveFrom.intervalJunTwo.SameMovement(waveTo.intervalJunOne) {
 					continue
 				}
 				// Check if intervals can connect
@@ -86,7 +88,7 @@ func CreateAdjustedSegments(segmentsWaves [][]*GreenWave) [][]*GreenWave {
 		newNextSegment := []*GreenWave{}
 		for _, waveFrom := range currentSegment {
 			for _, waveTo := range nextSegment {
-				if waveFrom.intervalJunTwo.PhaseIdx != waveTo.intervalJunOne.PhaseIdx {
+				if !waveFrom.intervalJunTwo.SameMovement(waveTo.intervalJunOne) {
 					continue
 				}
 				if intersection := waveFrom.intervalJunTwo.CanConnect(waveTo.intervalJunOne); intersection != nil {
@@ -162,7 +164,34 @@ func MergeGreenWaves(segmentsWaves [][]*GreenWave) []*ThroughGreenWave {
 		for _, wave := range adjustedWaves {
 			intervals = append(intervals, wave.intervalJunTwo)
 		}
-		throughWaves = append(throughWaves, NewThroughGreenWave(intervals))
+		throughWave := NewThroughGreenWave(intervals)
+		if robustBandwidth, ok := chainRobustBandwidth(adjustedWaves); ok {
+			throughWave.robustBandWidth = math.Min(robustBandwidth, throughWave.bandWidth)
+		}
+		throughWaves = append(throughWaves, throughWave)
 	}
 	return throughWaves
 }
+
+// chainRobustBandwidth calculates bandwidth of the chain which remains valid for every speed within the speed range.
+// Vehicle departing at time x from the first junction arrives at junction k within [x + minTravelTime_k; x + maxTravelTime_k]
+// (cumulative travel times), so the departure window is the intersection of [start_k - minTravelTime_k; end_k - maxTravelTime_k] over all junctions.
+// Returns false if chain does not contain speed range data.
+func chainRobustBandwidth(chain []*GreenWave) (float64, bool) {
+	if len(chain) == 0 || chain[0].tolerance == nil {
+		return 0, false
+	}
+	windowStart := chain[0].tolerance.greenJunOne.Start
+	windowEnd := chain[0].tolerance.greenJunOne.End
+	cumulativeMinTravelTime, cumulativeMaxTravelTime := 0.0, 0.0
+	for _, wave := range chain {
+		if wave.tolerance == nil {
+			return 0, false
+		}
+		cumulativeMinTravelTime += wave.tolerance.minTravelTime
+		cumulativeMaxTravelTime += wave.tolerance.maxTravelTime
+		windowStart = math.Max(windowStart, wave.tolerance.greenJunTwo.Start-cumulativeMinTravelTime)
+		windowEnd = math.Min(windowEnd, wave.tolerance.greenJunTwo.End-cumulativeMaxTravelTime)
+	}
+	return math.Max(0, windowEnd-windowStart), true
+}
@@ -38,3 +38,37 @@ func TestMergeGreenWaves(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeGreenWavesSpeedRange(t *testing.T) {
+	junctions := basicTestJuntions()
+	desiredSpeedKmh := 40.0
+
+	// Degenerate speed range gives the same bandwidth as the nominal one
+	throughGreenWaves := MergeGreenWaves(FindGreenWaves(junctions, desiredSpeedKmh, WithSpeedRange(40, 40)))
+	assert.Equalf(t, 2, len(throughGreenWaves), "Expected %d through green waves, got %d", 2, len(throughGreenWaves))
+	for i, throughGreenWave := range throughGreenWaves {
+		assert.InDeltaf(t, throughGreenWave.Bandwidth(), throughGreenWave.RobustBandwidth(), 1e-9, "Through green wave %d: robust bandwidth should match nominal one", i)
+	}
+
+	// Speeds within [38; 42] km/h
+	throughGreenWaves = MergeGreenWaves(FindGreenWaves(junctions, desiredSpeedKmh, WithSpeedRange(38, 42)))
+	correctBandwidths := []float64{3.5, 8.5}
+	correctRobustBandwidths := []float64{0, 4.7293}
+	assert.Equalf(t, len(correctBandwidths), len(throughGreenWaves), "Expected %d through green waves, got %d", len(correctBandwidths), len(throughGreenWaves))
+	for i, throughGreenWave := range throughGreenWaves {
+		assert.InDeltaf(t, correctBandwidths[i], throughGreenWave.Bandwidth(), 1e-4, "Through green wave %d: bandwidth mismatch", i)
+		assert.InDeltaf(t, correctRobustBandwidths[i], throughGreenWave.RobustBandwidth(), 1e-4, "Through green wave %d: robust bandwidth mismatch", i)
+	}
+
+	// Without speed range robust bandwidth is the nominal one
+	throughGreenWaves = MergeGreenWaves(FindGreenWaves(junctions, desiredSpeedKmh))
+	for i, throughGreenWave := range throughGreenWaves {
+		assert.Equalf(t, throughGreenWave.Bandwidth(), throughGreenWave.RobustBandwidth(), "Through green wave %d: robust bandwidth should match nominal one", i)
+	}
+}
+
+func TestSpeedRangeFromDistribution(t *testing.T) {
+	minSpeedKmh, maxSpeedKmh := SpeedRangeFromDistribution(50, 5, 0.6827)
+	assert.InDelta(t, 45.0, minSpeedKmh, 0.01, "Expected one standard deviation below mean")
+	assert.InDelta(t, 55.0, maxSpeedKmh, 0.01, "Expected one standard deviation above mean")
+}
@@ -173,3 +173,63 @@ func TestFindGreenWaves(t *testing.T) {
 		}
 	}
 }
+
+func TestFindGreenWavesInbound(t *testing.T) {
+	junctions := basicTestJuntions()
+	desiredSpeedKmh := 40.0
+	greenWaves := FindGreenWavesInbound(junctions, desiredSpeedKmh)
+	correctGreenWaves := [][]*GreenWave{
+		// Segment 0 (last junction -> penultimate junction)
+		{
+			NewGreenWave(
+				NewGreenInterval(0, 40, 41.5),
+				NewGreenInterval(0, 53.5, 55),
+				150,
+				13.5,
+			),
+			NewGreenWave(
+				NewGreenInterval(0, 48.5, 55),
+				NewGreenInterval(1, 62, 68.5),
+				150,
+				13.5,
+			),
+			NewGreenWave(
+				NewGreenInterval(1, 65, 66.5),
+				NewGreenInterval(1, 78.5, 80),
+				150,
+				13.5,
+			),
+		},
+		// Segment 1
+		{
+			NewGreenWave(
+				NewGreenInterval(0, 47.5, 55),
+				NewGreenInterval(1, 70, 77.5),
+				250,
+				22.5,
+			),
+		},
+		// Segment 2 (second junction -> first junction)
+		{
+			NewGreenWave(
+				NewGreenInterval(0, 32, 52),
+				NewGreenInterval(1, 50, 70),
+				200,
+				18.0,
+			),
+		},
+	}
+
+	assert.Equalf(t, len(correctGreenWaves), len(greenWaves), "Expected %d segments, got %d", len(correctGreenWaves), len(greenWaves))
+	for i, segmentGreenWaves := range greenWaves {
+		assert.Equalf(t, len(correctGreenWaves[i]), len(segmentGreenWaves), "Segment %d: Expected %d green waves, got %d", i, len(correctGreenWaves[i]), len(segmentGreenWaves))
+		for j, greenWave := range segmentGreenWaves {
+			assert.Equalf(t, correctGreenWaves[i][j], greenWave, "Segment %d, Green Wave %d: Expected %v, got %v", i, j, correctGreenWaves[i][j], greenWave)
+		}
+	}
+
+	// Two-way extraction should give the same results for each direction
+	outbound, inbound := FindTwoWayGreenWaves(junctions, desiredSpeedKmh, desiredSpeedKmh)
+	assert.Equal(t, FindGreenWaves(junctions, desiredSpeedKmh), outbound, "Outbound waves mismatch")
+	assert.Equal(t, greenWaves, inbound, "Inbound waves mismatch")
+}
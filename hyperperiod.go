@@ -0,0 +1,93 @@
+package greenwave
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultMaxHyperperiod is the default upper bound (in seconds) of the corridor hyperperiod (see WithMaxHyperperiod).
+const DefaultMaxHyperperiod = 3600
+
+// hyperperiodTicksPerSecond is the time resolution used for calculation of the hyperperiod (tenths of a second)
+const hyperperiodTicksPerSecond = 10
+
+// Hyperperiod returns the least common multiple of cycle lengths (in seconds) of the given junctions.
+// Junctions with non-positive cycle duration are ignored. Returns 0 if there is no junction with valid cycle.
+// Within the hyperperiod the signal timing of the whole corridor repeats itself, e.g. for 60 s and 120 s cycles it is 120 s.
+// Cycle lengths are considered with resolution of a tenth of a second. Returns +Inf if the hyperperiod does not fit into integer ticks.
+// See CorridorHyperperiod for the hyperperiod green waves are actually extracted over.
+func Hyperperiod(junctions []*Junction) float64 {
+	hyperperiod, ok := hyperperiodWithin(junctions, math.Inf(1))
+	if !ok {
+		return math.Inf(1)
+	}
+	return hyperperiod
+}
+
+// CorridorHyperperiod returns the hyperperiod (in seconds) green waves of the given junctions are extracted over with the given settings.
+// If the least common multiple of cycle lengths exceeds the limit (see WithMaxHyperperiod) then 0 and false are returned:
+// each junction is analysed over its own cycle in that case.
+func CorridorHyperperiod(junctions []*Junction, options ...func(*ExtractSettings)) (float64, bool) {
+	return hyperperiodWithin(junctions, newExtractSettings(options...).hyperperiodLimit())
+}
+
+// CommonCycle returns the cycle length (in seconds) shared by all given junctions.
+// Returns error if cycle lengths differ (e.g. half/double cycling) or there are no junctions.
+func CommonCycle(junctions []*Junction) (float64, error) {
+	if len(junctions) == 0 {
+		return 0, fmt.Errorf("no junctions")
+	}
+	cycle := junctions[0].totalDuration
+	for i, junction := range junctions {
+		if math.Abs(junction.totalDuration-cycle) > 1e-6 {
+			return 0, fmt.Errorf("junction %d has %g seconds cycle, expected %g", i, junction.totalDuration, cycle)
+		}
+	}
+	return cycle, nil
+}
+
+// hyperperiodWithin calculates the hyperperiod (see Hyperperiod) as long as it does not exceed the limit in seconds.
+// Returns false as soon as the limit is exceeded, so the least common multiple is never calculated in full for incommensurate cycles.
+func hyperperiodWithin(junctions []*Junction, limit float64) (float64, bool) {
+	limitTicks := math.MaxInt
+	if limit*hyperperiodTicksPerSecond < float64(math.MaxInt) {
+		limitTicks = int(math.Floor(limit*hyperperiodTicksPerSecond + 1e-9))
+	}
+	hyperperiod := 0
+	for _, junction := range junctions {
+		cycleTicks := int(math.Round(junction.totalDuration * hyperperiodTicksPerSecond))
+		if cycleTicks <= 0 {
+			continue
+		}
+		if hyperperiod == 0 {
+			hyperperiod = cycleTicks
+		} else {
+			multiplier := hyperperiod / gcd(hyperperiod, cycleTicks)
+			if multiplier > limitTicks/cycleTicks {
+				return 0, false // Exceeds the limit (or overflows)
+			}
+			hyperperiod = multiplier * cycleTicks
+		}
+		if hyperperiod > limitTicks {
+			return 0, false
+		}
+	}
+	return float64(hyperperiod) / hyperperiodTicksPerSecond, true
+}
+
+// gcd returns the greatest common divisor of two positive integers.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// wrapTime returns the given time wrapped into [0; period). Negative values are wrapped too.
+func wrapTime(t, period float64) float64 {
+	wrapped := math.Mod(t, period)
+	if wrapped < 0 {
+		wrapped += period
+	}
+	return wrapped
+}
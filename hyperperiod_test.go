@@ -0,0 +1,80 @@
+package greenwave
+
+import (
+	"math"
+	"testing"
+
+	"github.com/LdDl/greenwave/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func hyperperiodTestJunctions() []*Junction {
+	// Half cycling junction: 60 s cycle, green at [0; 30]
+	junctionOne := NewJunction([]*Phase{
+		NewPhase(0, []*Signal{
+			NewSignal(30, color.GREEN),
+			NewSignal(30, color.RED),
+		}),
+	}, WithID(1), WithPoint(Point{X: 0, Y: 0}))
+	// Double cycling junction: 120 s cycle, green at [80; 120]
+	junctionTwo := NewJunction([]*Phase{
+		NewPhase(0, []*Signal{
+			NewSignal(80, color.RED),
+			NewSignal(40, color.GREEN),
+		}),
+	}, WithID(2), WithPoint(Point{X: 0, Y: 100}))
+	return []*Junction{junctionOne, junctionTwo}
+}
+
+func TestHyperperiod(t *testing.T) {
+	assert.Equal(t, 120.0, Hyperperiod(hyperperiodTestJunctions()), "Hyperperiod of 60 s and 120 s cycles mismatch")
+	assert.Equal(t, 85.0, Hyperperiod(basicTestJuntions()), "Hyperperiod of equal cycles should be the cycle itself")
+	assert.Equal(t, 0.0, Hyperperiod([]*Junction{}), "Hyperperiod of empty corridor should be zero")
+}
+
+func TestFindGreenWavesHyperperiod(t *testing.T) {
+	junctions := hyperperiodTestJunctions()
+	// 100 m at 36 km/h gives 10 s of travel time
+	greenWaves := FindGreenWaves(junctions, 36)
+	assert.Equal(t, 1, len(greenWaves), "Expected single segment")
+	assert.Equal(t, 1, len(greenWaves[0]), "Expected single green wave within the hyperperiod")
+	wave := greenWaves[0][0]
+	// Second green of the half cycling junction [60; 90] reaches green of the double cycling one
+	assert.InDelta(t, 70.0, wave.intervalJunOne.Start, 1e-9, "Departure start mismatch")
+	assert.InDelta(t, 90.0, wave.intervalJunOne.End, 1e-9, "Departure end mismatch")
+	assert.InDelta(t, 80.0, wave.intervalJunTwo.Start, 1e-9, "Arrival start mismatch")
+	assert.InDelta(t, 100.0, wave.intervalJunTwo.End, 1e-9, "Arrival end mismatch")
+	assert.InDelta(t, 20.0, wave.bandwidth, 1e-9, "Bandwidth mismatch")
+
+	// Offset is applied within own cycle of the junction and repeated across the hyperperiod
+	junctions[0].SetOffset(-50)
+	greenWaves = FindGreenWaves(junctions, 36)
+	assert.Equal(t, 1, len(greenWaves[0]), "Expected single green wave for shifted junction")
+	assert.InDelta(t, 70.0, greenWaves[0][0].intervalJunOne.Start, 1e-9, "Departure start mismatch")
+	assert.InDelta(t, 100.0, greenWaves[0][0].intervalJunOne.End, 1e-9, "Departure end mismatch")
+
+	// Without unrolling each junction is analysed over its own cycle only
+	junctions[0].SetOffset(0)
+	greenWaves = FindGreenWaves(junctions, 36, WithMaxHyperperiod(60))
+	assert.Equal(t, 0, len(greenWaves[0]), "Expected no green waves without hyperperiod analysis")
+}
+
+func TestCorridorHyperperiod(t *testing.T) {
+	junctions := hyperperiodTestJunctions()
+	hyperperiod, ok := CorridorHyperperiod(junctions)
+	assert.True(t, ok, "Hyperperiod within the default limit should be used")
+	assert.Equal(t, 120.0, hyperperiod, "Hyperperiod mismatch")
+	hyperperiod, ok = CorridorHyperperiod(junctions, WithMaxHyperperiod(60))
+	assert.False(t, ok, "Hyperperiod over the limit should not be used")
+	assert.Equal(t, 0.0, hyperperiod, "Hyperperiod over the limit should be reported as zero")
+
+	// Pairwise coprime fractional cycles: the least common multiple does not fit into integer ticks
+	incommensurate := make([]*Junction, 0)
+	for _, cycle := range []float64{60.1, 60.7, 61.1, 61.3, 61.7, 61.9, 62.3, 62.9, 63.1, 63.7, 64.1, 64.3} {
+		incommensurate = append(incommensurate, NewJunction([]*Phase{NewPhase(0, []*Signal{NewSignal(cycle, color.GREEN)})}))
+	}
+	assert.True(t, math.IsInf(Hyperperiod(incommensurate), 1), "Overflowed hyperperiod should be infinite")
+	hyperperiod, ok = CorridorHyperperiod(incommensurate, WithMaxHyperperiod(0))
+	assert.False(t, ok, "Overflowed hyperperiod should not be used even without limit")
+	assert.Equal(t, 0.0, hyperperiod, "Overflowed hyperperiod should be reported as zero")
+}
@@ -11,16 +11,24 @@ type Junction struct {
 	// Cycle is a list of phases that define the traffic light cycle for this junction
 	Cycle []*Phase
 	// Total duration of the cycle in seconds
-	totalDuration int
-	// Offset of the cycle
-	offset int
+	totalDuration float64
+	// Offset of the cycle in seconds
+	offset float64
 	// Location of the junction
 	point Point
+	// Effective green policy. If nil then corridor policy (or raw GREEN/GREENPRIORITY signals) is used
+	effectiveGreen *EffectiveGreen
+	// Coordinated movement. If nil then every green is considered and waves are connected within the same phase only
+	movement *Movement
+	// Named signal groups with their own color timelines across the cycle
+	signalGroups []*SignalGroup
+	// Offsets which optimizers could choose. If nil then offset is not restricted
+	offsetConstraint *OffsetConstraint
 }
 
 // NewJunction creates a new Junction instance with the specified ID, label, cycle (list of phases)
 func NewJunction(cycle []*Phase, options ...func(*Junction)) *Junction {
-	totalDuration := 0
+	totalDuration := 0.0
 	for _, phase := range cycle {
 		totalDuration += phase.totalSeconds
 	}
@@ -38,12 +46,12 @@ func NewJunction(cycle []*Phase, options ...func(*Junction)) *Junction {
 }
 
 // GetOffset returns the offset for the junction.
-func (jun *Junction) GetOffset() int {
+func (jun *Junction) GetOffset() float64 {
 	return jun.offset
 }
 
 // SetOffset sets the offset for the junction.
-func (jun *Junction) SetOffset(offset int) {
+func (jun *Junction) SetOffset(offset float64) {
 	jun.offset = offset
 }
 
@@ -68,8 +76,55 @@ func WithPoint(point Point) func(*Junction) {
 	}
 }
 
+// WithEffectiveGreen is an option function that sets the effective green policy for the junction.
+func WithEffectiveGreen(effectiveGreen *EffectiveGreen) func(*Junction) {
+	return func(j *Junction) {
+		j.effectiveGreen = effectiveGreen
+	}
+}
+
+// GetEffectiveGreen returns the effective green policy of the junction. Could be nil.
+func (jun *Junction) GetEffectiveGreen() *EffectiveGreen {
+	return jun.effectiveGreen
+}
+
+// WithMovement is an option function that declares the coordinated movement for the junction.
+func WithMovement(movement *Movement) func(*Junction) {
+	return func(j *Junction) {
+		j.movement = movement
+	}
+}
+
+// GetMovement returns the coordinated movement of the junction. Could be nil.
+func (jun *Junction) GetMovement() *Movement {
+	return jun.movement
+}
+
+// WithSignalGroups is an option function that sets named signal groups for the junction.
+func WithSignalGroups(signalGroups ...*SignalGroup) func(*Junction) {
+	return func(j *Junction) {
+		j.signalGroups = signalGroups
+	}
+}
+
+// GetSignalGroups returns signal groups of the junction.
+// Returns slice, do not modify it
+func (jun *Junction) GetSignalGroups() []*SignalGroup {
+	return jun.signalGroups
+}
+
+// GetSignalGroup returns the signal group with the given name or nil if there is no such group.
+func (jun *Junction) GetSignalGroup(name string) *SignalGroup {
+	for _, group := range jun.signalGroups {
+		if group.Name == name {
+			return group
+		}
+	}
+	return nil
+}
+
 // GetTotalDuration returns the total duration of the junction's cycle in seconds.
-func (jun *Junction) GetTotalDuration() int {
+func (jun *Junction) GetTotalDuration() float64 {
 	return jun.totalDuration
 }
 
@@ -78,31 +133,192 @@ func (jun *Junction) GetPoint() Point {
 	return jun.point
 }
 
-func (jun *Junction) GetGreenIntervals() []*GreenInterval {
+// GetGreenIntervals returns usable (green) intervals of the junction cycle considering junction's effective green policy.
+// Without policy exactly GREEN and GREENPRIORITY signals are considered as usable time.
+// Optional signal group selector picks the timeline of the named signal group (see SignalGroup) instead of the phases one.
+func (jun *Junction) GetGreenIntervals(signalGroup ...string) []*GreenInterval {
+	groupName := ""
+	if len(signalGroup) > 0 {
+		groupName = signalGroup[0]
+	}
+	return jun.greenIntervals(nil, groupName)
+}
+
+// usableBlock is a continuous block of usable signals before applying effective green policy
+type usableBlock struct {
+	phaseIdx int
+	start    float64
+	end      float64
+	// Signal which follows the block (could be in the next phase)
+	next *Signal
+}
+
+// greenIntervals returns usable intervals considering junction's effective green policy or the fallback one if junction has no policy.
+// If signal group name is not empty then timeline of that group is used, otherwise the phases timeline is used:
+// consecutive usable signals within the same phase are merged into a single interval and if junction has coordinated movement
+// then only its phases are considered and their consecutive greens are merged (see Movement).
+// Start-up lost time is cut from the beginning of each interval and part of the following YELLOW signal is added to its end.
+func (jun *Junction) greenIntervals(fallback *EffectiveGreen, signalGroup string) []*GreenInterval {
 	intervals := make([]*GreenInterval, 0)
 
 	cycleDuration := jun.totalDuration
 	if cycleDuration <= 0 {
 		return intervals // No valid cycle duration, return empty intervals
 	}
+	policy := jun.effectiveGreen
+	if policy == nil {
+		policy = fallback
+	}
+
+	var blocks []*usableBlock
+	// Intervals of signal group or declared movement connect regardless of phase indices
+	movement := false
+	if signalGroup != "" {
+		group := jun.GetSignalGroup(signalGroup)
+		if group == nil {
+			return intervals // Unknown signal group, there is no green for it
+		}
+		blocks = jun.signalGroupBlocks(group, policy)
+		movement = true
+	} else {
+		blocks = jun.phaseBlocks(policy)
+		if jun.movement != nil {
+			blocks = jun.movement.mergeBlocks(blocks, cycleDuration)
+			movement = true
+		}
+	}
 
-	currentTime := 0
+	for _, block := range blocks {
+		start := block.start + policy.startupLostTime()
+		effectiveEnd := block.end
+		if block.next.Color == color.YELLOW {
+			effectiveEnd += policy.yellowExtension(block.next.Duration)
+		}
+		if effectiveEnd-start <= 0 {
+			continue // Whole interval has been lost
+		}
+		// End could exceed cycle duration if yellow extension (or merge) wraps to the beginning of the cycle
+		interval := NewGreenInterval(block.phaseIdx, start, effectiveEnd)
+		interval.movement = movement
+		intervals = append(intervals, interval)
+	}
+	return intervals
+}
+
+// phaseBlocks returns blocks of consecutive usable signals within each phase.
+func (jun *Junction) phaseBlocks(policy *EffectiveGreen) []*usableBlock {
+	// Flatten signals to find the signal which follows the usable block (could be in the next phase)
+	type timedSignal struct {
+		phaseIdx int
+		start    float64
+		signal   *Signal
+	}
+	timeline := make([]timedSignal, 0)
+	currentTime := 0.0
 	for phaseIdx, phase := range jun.Cycle {
-		phaseEnd := currentTime + phase.totalSeconds
 		signalStart := currentTime
 		for _, signal := range phase.Signals {
-			if signal.Color == color.GREEN || signal.Color == color.GREENPRIORITY {
-				start := signalStart
-				end := signalStart + signal.Duration
-				if end == cycleDuration {
-					intervals = append(intervals, NewGreenInterval(phaseIdx, float64(start%cycleDuration), float64(end)))
-				} else {
-					intervals = append(intervals, NewGreenInterval(phaseIdx, float64(start%cycleDuration), float64(end%cycleDuration)))
-				}
-			}
+			timeline = append(timeline, timedSignal{phaseIdx: phaseIdx, start: signalStart, signal: signal})
 			signalStart += signal.Duration
 		}
-		currentTime = phaseEnd
+		currentTime += phase.totalSeconds
 	}
-	return intervals
+
+	blocks := make([]*usableBlock, 0)
+	for i := 0; i < len(timeline); i++ {
+		if !policy.IsUsable(timeline[i].signal.Color) {
+			continue
+		}
+		blockStart := timeline[i]
+		end := blockStart.start + blockStart.signal.Duration
+		// Merge consecutive usable signals of the same phase
+		for i+1 < len(timeline) && timeline[i+1].phaseIdx == blockStart.phaseIdx && policy.IsUsable(timeline[i+1].signal.Color) {
+			i++
+			end = timeline[i].start + timeline[i].signal.Duration
+		}
+		blocks = append(blocks, &usableBlock{
+			phaseIdx: blockStart.phaseIdx,
+			start:    blockStart.start,
+			end:      end,
+			next:     timeline[(i+1)%len(timeline)].signal,
+		})
+	}
+	return blocks
+}
+
+// signalGroupBlocks returns blocks of consecutive usable signals of the signal group timeline.
+// Phase index of the block is the index of the phase in which the block starts.
+func (jun *Junction) signalGroupBlocks(group *SignalGroup, policy *EffectiveGreen) []*usableBlock {
+	blocks := make([]*usableBlock, 0)
+	signals := group.Signals
+	signalStart := 0.0
+	for i := 0; i < len(signals); i++ {
+		if !policy.IsUsable(signals[i].Color) {
+			signalStart += signals[i].Duration
+			continue
+		}
+		start := signalStart
+		signalStart += signals[i].Duration
+		for i+1 < len(signals) && policy.IsUsable(signals[i+1].Color) {
+			i++
+			signalStart += signals[i].Duration
+		}
+		blocks = append(blocks, &usableBlock{
+			phaseIdx: jun.phaseAt(start),
+			start:    start,
+			end:      signalStart,
+			next:     signals[(i+1)%len(signals)],
+		})
+	}
+	// Green of the group could continue through the cycle end
+	if len(blocks) > 1 && blocks[0].start == 0 && blocks[len(blocks)-1].end == signalStart {
+		last := blocks[len(blocks)-1]
+		last.end = blocks[0].end + signalStart
+		last.next = blocks[0].next
+		blocks = blocks[1:]
+	}
+	return blocks
+}
+
+// phaseAt returns index of the phase which is active at the given time of the cycle.
+func (jun *Junction) phaseAt(cycleTime float64) int {
+	phaseStart := 0.0
+	for phaseIdx, phase := range jun.Cycle {
+		if cycleTime < phaseStart+phase.totalSeconds {
+			return phaseIdx
+		}
+		phaseStart += phase.totalSeconds
+	}
+	return len(jun.Cycle) - 1
+}
+
+// clone returns a copy of the junction with its own phases, signals and signal groups.
+// Effective green policy, movement and offset constraint are shared with the original junction.
+func (jun *Junction) clone() *Junction {
+	cloned := *jun
+	cloned.Cycle = make([]*Phase, len(jun.Cycle))
+	for i, phase := range jun.Cycle {
+		clonedPhase := *phase
+		clonedPhase.Signals = cloneSignals(phase.Signals)
+		cloned.Cycle[i] = &clonedPhase
+	}
+	if jun.signalGroups != nil {
+		cloned.signalGroups = make([]*SignalGroup, len(jun.signalGroups))
+		for i, group := range jun.signalGroups {
+			clonedGroup := *group
+			clonedGroup.Signals = cloneSignals(group.Signals)
+			cloned.signalGroups[i] = &clonedGroup
+		}
+	}
+	return &cloned
+}
+
+// cloneSignals returns copies of the given signals.
+func cloneSignals(signals []*Signal) []*Signal {
+	cloned := make([]*Signal, len(signals))
+	for i, signal := range signals {
+		clonedSignal := *signal
+		cloned[i] = &clonedSignal
+	}
+	return cloned
 }
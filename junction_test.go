@@ -1,6 +1,7 @@
 package greenwave
 
 import (
+	"math"
 	"testing"
 
 	"github.com/LdDl/greenwave/color"
@@ -70,10 +71,35 @@ func basicTestJuntions() []*Junction {
 
 func TestCycleDurationCorrectness(t *testing.T) {
 	junctions := basicTestJuntions()
-	correctDuration := 85
+	correctDuration := 85.0
 	for i, junction := range junctions {
 		if junction.totalDuration != correctDuration {
-			t.Errorf("Junction at position %d has incorrect total duration: got %d, want %d", i, junction.totalDuration, correctDuration)
+			t.Errorf("Junction at position %d has incorrect total duration: got %g, want %g", i, junction.totalDuration, correctDuration)
 		}
 	}
 }
+
+func TestFractionalDurations(t *testing.T) {
+	junction := NewJunction([]*Phase{
+		NewPhase(0, []*Signal{
+			NewSignal(12.5, color.GREEN),
+			NewSignal(3, color.YELLOW),
+			NewSignal(30.3, color.RED),
+		}),
+	})
+	if math.Abs(junction.GetTotalDuration()-45.8) > 1e-9 {
+		t.Errorf("Incorrect total duration: got %g, want %g", junction.GetTotalDuration(), 45.8)
+	}
+	junction.SetOffset(40.3)
+	intervals := adjustIntervalsByOffset(junction, nil, "", 0)
+	// Green [0; 12.5] shifted by 40.3 wraps the cycle end: [40.3; 45.8] and [0; 7]
+	if len(intervals) != 2 {
+		t.Fatalf("Incorrect number of intervals: got %d, want %d", len(intervals), 2)
+	}
+	if math.Abs(intervals[0].Start-40.3) > 1e-9 || math.Abs(intervals[0].End-45.8) > 1e-9 {
+		t.Errorf("Incorrect first interval: got [%g; %g], want [40.3; 45.8]", intervals[0].Start, intervals[0].End)
+	}
+	if math.Abs(intervals[1].Start) > 1e-9 || math.Abs(intervals[1].End-7) > 1e-9 {
+		t.Errorf("Incorrect second interval: got [%g; %g], want [0; 7]", intervals[1].Start, intervals[1].End)
+	}
+}
@@ -0,0 +1,70 @@
+package greenwave
+
+// Movement declares which phases of the junction serve the coordinated (through) movement.
+// Greens of such phases are merged when they follow each other, and waves are connected through them regardless of phase indices.
+type Movement struct {
+	// Phases contains indices of phases (positions in Junction.Cycle) which serve the movement
+	Phases []int
+	// MaxGap is the maximum gap in seconds between consecutive greens of the movement which is still merged into a single interval
+	MaxGap float64
+}
+
+// NewMovement creates a new Movement instance served by the specified phases (positions in Junction.Cycle).
+func NewMovement(phases []int, options ...func(*Movement)) *Movement {
+	movement := &Movement{
+		Phases: phases,
+		MaxGap: 0,
+	}
+	for _, option := range options {
+		option(movement)
+	}
+	return movement
+}
+
+// WithMaxGap is an option function that sets the maximum gap between consecutive greens of the movement which is merged.
+func WithMaxGap(maxGap float64) func(*Movement) {
+	return func(m *Movement) {
+		m.MaxGap = maxGap
+	}
+}
+
+// Serves checks if the phase with the given index serves the movement.
+func (m *Movement) Serves(phaseIdx int) bool {
+	for _, idx := range m.Phases {
+		if idx == phaseIdx {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeBlocks keeps only blocks of the movement phases and merges consecutive ones (including the pair wrapped by the cycle end).
+// Blocks are expected to be sorted by start time.
+func (m *Movement) mergeBlocks(blocks []*usableBlock, cycleDuration float64) []*usableBlock {
+	merged := make([]*usableBlock, 0, len(blocks))
+	for _, block := range blocks {
+		if !m.Serves(block.phaseIdx) {
+			continue
+		}
+		if len(merged) > 0 {
+			last := merged[len(merged)-1]
+			if block.start-last.end <= m.MaxGap+eps {
+				last.end = block.end
+				last.next = block.next
+				continue
+			}
+		}
+		merged = append(merged, &usableBlock{phaseIdx: block.phaseIdx, start: block.start, end: block.end, next: block.next})
+	}
+	// Movement green could continue through the cycle end
+	if len(merged) > 1 {
+		first := merged[0]
+		last := merged[len(merged)-1]
+		if first.start+cycleDuration-last.end <= m.MaxGap+eps {
+			last.end = first.end + cycleDuration
+			last.next = first.next
+			merged = merged[1:]
+		}
+	}
+	return merged
+}
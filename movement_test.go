@@ -0,0 +1,88 @@
+package greenwave
+
+import (
+	"testing"
+
+	"github.com/LdDl/greenwave/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func movementTestCycle() []*Phase {
+	return []*Phase{
+		NewPhase(0, []*Signal{
+			NewSignal(30, color.GREEN),
+		}),
+		NewPhase(1, []*Signal{
+			NewSignal(20, color.GREEN),
+			NewSignal(30, color.RED),
+		}),
+	}
+}
+
+func TestMovementGreenIntervals(t *testing.T) {
+	// Without movement greens of different phases are separate intervals
+	junction := NewJunction(movementTestCycle())
+	greenIntervals := junction.GetGreenIntervals()
+	assert.Equal(t, 2, len(greenIntervals), "Expected separate intervals for each phase")
+
+	// Through movement is served by both phases: single merged interval
+	junction = NewJunction(movementTestCycle(), WithMovement(NewMovement([]int{0, 1})))
+	greenIntervals = junction.GetGreenIntervals()
+	assert.Equal(t, 1, len(greenIntervals), "Expected merged interval")
+	assert.Equal(t, 0, greenIntervals[0].PhaseIdx, "Merged interval should keep the first phase index")
+	assert.InDelta(t, 0.0, greenIntervals[0].Start, 1e-9, "Start mismatch")
+	assert.InDelta(t, 50.0, greenIntervals[0].End, 1e-9, "End mismatch")
+
+	// Only declared phases are considered
+	junction = NewJunction(movementTestCycle(), WithMovement(NewMovement([]int{1})))
+	greenIntervals = junction.GetGreenIntervals()
+	assert.Equal(t, 1, len(greenIntervals), "Expected interval of the declared phase only")
+	assert.InDelta(t, 30.0, greenIntervals[0].Start, 1e-9, "Start mismatch")
+	assert.InDelta(t, 50.0, greenIntervals[0].End, 1e-9, "End mismatch")
+
+	// Gap between greens is merged if it does not exceed the maximum gap; merge through the cycle end
+	cycle := []*Phase{
+		NewPhase(0, []*Signal{
+			NewSignal(10, color.GREEN),
+			NewSignal(3, color.YELLOW),
+			NewSignal(37, color.RED),
+		}),
+		NewPhase(1, []*Signal{
+			NewSignal(2, color.RED),
+			NewSignal(20, color.GREEN),
+			NewSignal(2, color.RED),
+			NewSignal(6, color.GREEN),
+		}),
+	}
+	junction = NewJunction(cycle, WithMovement(NewMovement([]int{0, 1}, WithMaxGap(2))))
+	greenIntervals = junction.GetGreenIntervals()
+	assert.Equal(t, 1, len(greenIntervals), "Expected single interval wrapped by the cycle end")
+	assert.Equal(t, 1, greenIntervals[0].PhaseIdx, "Phase index mismatch")
+	assert.InDelta(t, 52.0, greenIntervals[0].Start, 1e-9, "Start mismatch")
+	assert.InDelta(t, 90.0, greenIntervals[0].End, 1e-9, "End mismatch")
+}
+
+func TestMergeGreenWavesMovement(t *testing.T) {
+	prepareJunctions := func(options ...func(*Junction)) []*Junction {
+		return []*Junction{
+			NewJunction(movementTestCycle(), append(options, WithPoint(Point{X: 0, Y: 0}))...),
+			NewJunction(movementTestCycle(), append(options, WithPoint(Point{X: 0, Y: 100}))...),
+			NewJunction(movementTestCycle(), append(options, WithPoint(Point{X: 0, Y: 200}))...),
+		}
+	}
+	desiredSpeedKmh := 36.0 // 10 seconds per segment
+
+	// Phase boundary splits the band
+	throughGreenWaves := MergeGreenWaves(FindGreenWaves(prepareJunctions(), desiredSpeedKmh))
+	maxBandwidth := 0.0
+	for _, wave := range throughGreenWaves {
+		maxBandwidth = max(maxBandwidth, wave.Bandwidth())
+	}
+	assert.InDelta(t, 10.0, maxBandwidth, 1e-9, "Expected band limited by the phase boundary")
+
+	// Coordinated movement over both phases gives continuous band
+	throughGreenWaves = MergeGreenWaves(FindGreenWaves(prepareJunctions(WithMovement(NewMovement([]int{0, 1}))), desiredSpeedKmh))
+	assert.Equal(t, 1, len(throughGreenWaves), "Expected single through green wave")
+	assert.Equal(t, 3, throughGreenWaves[0].Depth(), "Depth mismatch")
+	assert.InDelta(t, 30.0, throughGreenWaves[0].Bandwidth(), 1e-9, "Bandwidth mismatch")
+}
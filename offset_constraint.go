@@ -0,0 +1,260 @@
+package greenwave
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// OffsetConstraintType defines how the offset of the junction is restricted
+type OffsetConstraintType uint8
+
+const (
+	// OFFSET_CONSTRAINT_FIXED means that the offset must not change (e.g. junction belongs to another coordinated system)
+	OFFSET_CONSTRAINT_FIXED OffsetConstraintType = iota
+	// OFFSET_CONSTRAINT_WINDOW means that the offset must be within the window
+	OFFSET_CONSTRAINT_WINDOW
+	// OFFSET_CONSTRAINT_DISCRETE means that the offset must be one of the allowed values
+	OFFSET_CONSTRAINT_DISCRETE
+)
+
+var offsetConstraintTypeToStr = [...]string{"fixed", "window", "discrete"}
+
+// String returns the string representation of the OffsetConstraintType
+func (constraintType OffsetConstraintType) String() string {
+	return offsetConstraintTypeToStr[constraintType]
+}
+
+// OffsetConstraint restricts offsets of the junction which optimizers could choose.
+// Offsets are in the same time frame as Junction offsets and are taken modulo the junction cycle.
+type OffsetConstraint struct {
+	constraintType OffsetConstraintType
+	// min and max are bounds of the window. Both are equal to the offset for fixed constraint
+	min float64
+	max float64
+	// offsets are allowed values for discrete constraint
+	offsets []float64
+}
+
+// NewFixedOffset creates the constraint which pins the offset of the junction
+func NewFixedOffset(offset float64) *OffsetConstraint {
+	return &OffsetConstraint{
+		constraintType: OFFSET_CONSTRAINT_FIXED,
+		min:            offset,
+		max:            offset,
+	}
+}
+
+// NewOffsetWindow creates the constraint which keeps the offset of the junction within [min; max].
+// Bounds are taken modulo the cycle, so the window could wrap the cycle end, e.g. [-10; 10] is "within ±10 seconds of zero".
+// Window which is not shorter than the cycle does not restrict the offset.
+func NewOffsetWindow(min, max float64) *OffsetConstraint {
+	if min > max {
+		min, max = max, min
+	}
+	return &OffsetConstraint{
+		constraintType: OFFSET_CONSTRAINT_WINDOW,
+		min:            min,
+		max:            max,
+	}
+}
+
+// NewDiscreteOffsets creates the constraint which allows the given offsets of the junction only
+func NewDiscreteOffsets(offsets ...float64) *OffsetConstraint {
+	sorted := slices.Clone(offsets)
+	slices.Sort(sorted)
+	constraint := &OffsetConstraint{
+		constraintType: OFFSET_CONSTRAINT_DISCRETE,
+		offsets:        sorted,
+	}
+	if len(sorted) > 0 {
+		constraint.min, constraint.max = sorted[0], sorted[len(sorted)-1]
+	}
+	return constraint
+}
+
+// WithOffsetConstraint is an option function that restricts offsets of the junction which optimizers could choose.
+func WithOffsetConstraint(constraint *OffsetConstraint) func(*Junction) {
+	return func(j *Junction) {
+		j.offsetConstraint = constraint
+	}
+}
+
+// GetOffsetConstraint returns the offset constraint of the junction. Nil means that offset is not restricted
+func (jun *Junction) GetOffsetConstraint() *OffsetConstraint {
+	return jun.offsetConstraint
+}
+
+// Type returns the type of the constraint
+func (constraint *OffsetConstraint) Type() OffsetConstraintType {
+	return constraint.constraintType
+}
+
+// Bounds returns bounds of the window. Both bounds are equal to the offset for fixed constraint,
+// the smallest and the largest allowed values are returned for discrete constraint
+func (constraint *OffsetConstraint) Bounds() (min float64, max float64) {
+	return constraint.min, constraint.max
+}
+
+// Offsets returns allowed values of discrete constraint in ascending order
+// Returns slice, do not modify it
+func (constraint *OffsetConstraint) Offsets() []float64 {
+	return constraint.offsets
+}
+
+// Allows returns true if the offset satisfies the constraint for the given cycle length
+func (constraint *OffsetConstraint) Allows(offset, cycle float64) bool {
+	return math.Abs(circularDelta(offset, constraint.project(offset, cycle), cycle)) <= eps
+}
+
+// project returns the allowed offset (within [0; cycle)) nearest to the given one on the cycle circle
+func (constraint *OffsetConstraint) project(offset, cycle float64) float64 {
+	offset = wrapTime(offset, cycle)
+	switch constraint.constraintType {
+	case OFFSET_CONSTRAINT_WINDOW:
+		width := constraint.max - constraint.min
+		if width >= cycle {
+			return offset
+		}
+		shift := wrapTime(offset-constraint.min, cycle)
+		if shift <= width {
+			return offset
+		}
+		// Outside of the window: the nearest bound
+		if shift-width < cycle-shift {
+			return wrapTime(constraint.max, cycle)
+		}
+		return wrapTime(constraint.min, cycle)
+	case OFFSET_CONSTRAINT_DISCRETE:
+		if len(constraint.offsets) == 0 {
+			return offset
+		}
+		nearest := wrapTime(constraint.offsets[0], cycle)
+		for _, allowed := range constraint.offsets[1:] {
+			allowed = wrapTime(allowed, cycle)
+			if math.Abs(circularDelta(offset, allowed, cycle)) < math.Abs(circularDelta(offset, nearest, cycle)) {
+				nearest = allowed
+			}
+		}
+		return nearest
+	default:
+		return wrapTime(constraint.min, cycle)
+	}
+}
+
+// random returns random allowed offset within [0; cycle)
+func (constraint *OffsetConstraint) random(random *rand.Rand, cycle float64) float64 {
+	switch constraint.constraintType {
+	case OFFSET_CONSTRAINT_WINDOW:
+		if constraint.max-constraint.min >= cycle {
+			return randomFloat(random, 0, cycle)
+		}
+		return wrapTime(randomFloat(random, constraint.min, constraint.max), cycle)
+	case OFFSET_CONSTRAINT_DISCRETE:
+		if len(constraint.offsets) == 0 {
+			return randomFloat(random, 0, cycle)
+		}
+		return wrapTime(constraint.offsets[random.IntN(len(constraint.offsets))], cycle)
+	default:
+		return wrapTime(constraint.min, cycle)
+	}
+}
+
+// offsetSpace contains allowed offsets of each junction for optimizers.
+// If no junction has own constraint then offsets are relative: the first junction is the reference one and its offset is fixed at 0.
+// Otherwise constraints are in absolute time, so offsets of unconstrained junctions (the first one too) are free.
+type offsetSpace struct {
+	// cycleLengths contains the offset range of each junction in seconds
+	cycleLengths []float64
+	// constraints of each junction. Nil means that offset is not restricted
+	constraints []*OffsetConstraint
+	// variables contains indices of junctions which offsets are optimized
+	variables []int
+	// constrained is true if any junction has own constraint
+	constrained bool
+}
+
+// newOffsetSpace creates offsets space for the junctions with the given offset ranges (usually cycle lengths)
+func newOffsetSpace(junctions []*Junction, cycleLengths []float64) *offsetSpace {
+	space := &offsetSpace{
+		cycleLengths: cycleLengths,
+		constraints:  make([]*OffsetConstraint, len(junctions)),
+		variables:    make([]int, 0, len(junctions)),
+	}
+	for i, junction := range junctions {
+		space.constraints[i] = junction.offsetConstraint
+		if junction.offsetConstraint != nil {
+			space.constrained = true
+		}
+	}
+	if !space.constrained && len(junctions) > 0 {
+		space.constraints[0] = NewFixedOffset(0) // Reference junction
+	}
+	for i := range junctions {
+		if space.constraints[i].fixed() {
+			continue
+		}
+		space.variables = append(space.variables, i)
+	}
+	return space
+}
+
+// withCycles returns the space of the same junctions with other offset ranges (e.g. cycle lengths of the individual in free cycle green split mode)
+func (space *offsetSpace) withCycles(cycleLengths []float64) *offsetSpace {
+	scaled := *space
+	scaled.cycleLengths = cycleLengths
+	return &scaled
+}
+
+// fixed returns true if the constraint allows a single offset. Nil constraint is not fixed
+func (constraint *OffsetConstraint) fixed() bool {
+	return constraint != nil && (constraint.constraintType == OFFSET_CONSTRAINT_FIXED ||
+		(constraint.constraintType == OFFSET_CONSTRAINT_DISCRETE && len(constraint.offsets) == 1))
+}
+
+// initial returns offsets with fixed junctions set to their offsets and the others set to the allowed offset nearest to 0
+func (space *offsetSpace) initial() []float64 {
+	offsets := make([]float64, len(space.cycleLengths))
+	for i := range offsets {
+		offsets[i] = space.project(i, 0)
+	}
+	return offsets
+}
+
+// random returns random allowed offset of the junction
+func (space *offsetSpace) random(random *rand.Rand, junctionIdx int) float64 {
+	if space.constraints[junctionIdx] == nil {
+		return randomFloat(random, 0, space.cycleLengths[junctionIdx])
+	}
+	return space.constraints[junctionIdx].random(random, space.cycleLengths[junctionIdx])
+}
+
+// perturb returns the offset of the junction shifted randomly within [-maxDelta; maxDelta] and wrapped by the cycle.
+// Discrete offsets are perturbed by picking random allowed value
+func (space *offsetSpace) perturb(random *rand.Rand, junctionIdx int, offset float64, maxDelta float64) float64 {
+	if constraint := space.constraints[junctionIdx]; constraint != nil && constraint.constraintType == OFFSET_CONSTRAINT_DISCRETE {
+		return space.random(random, junctionIdx)
+	}
+	return space.project(junctionIdx, offset+randomFloat(random, -maxDelta, maxDelta))
+}
+
+// project returns the allowed offset of the junction nearest to the given one. Result is wrapped by the cycle
+func (space *offsetSpace) project(junctionIdx int, offset float64) float64 {
+	if space.constraints[junctionIdx] == nil {
+		return wrapTime(offset, space.cycleLengths[junctionIdx])
+	}
+	return space.constraints[junctionIdx].project(offset, space.cycleLengths[junctionIdx])
+}
+
+// round rounds offsets to the resolution (see RoundOffsets) and projects them onto constraints, so constraints take precedence over the resolution.
+// Returns new slice.
+func (space *offsetSpace) round(offsets []float64, resolution float64) []float64 {
+	rounded := RoundOffsets(offsets, space.cycleLengths, resolution)
+	if !space.constrained {
+		return rounded
+	}
+	for i := range rounded {
+		rounded[i] = space.project(i, rounded[i])
+	}
+	return rounded
+}
@@ -0,0 +1,141 @@
+package greenwave
+
+import (
+	"testing"
+
+	"github.com/LdDl/greenwave/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetConstraintProject(t *testing.T) {
+	cycle := 85.0
+	window := NewOffsetWindow(10, -10)
+	assert.Equal(t, OFFSET_CONSTRAINT_WINDOW, window.Type(), "Wrong constraint type")
+	minOffset, maxOffset := window.Bounds()
+	assert.Equal(t, -10.0, minOffset, "Window bounds should be ordered")
+	assert.Equal(t, 10.0, maxOffset, "Window bounds should be ordered")
+	assert.InDelta(t, 5.0, window.project(5, cycle), 1e-9, "Offset within the window should be kept")
+	assert.InDelta(t, 80.0, window.project(80, cycle), 1e-9, "Offset within the wrapped part of the window should be kept")
+	assert.InDelta(t, 10.0, window.project(30, cycle), 1e-9, "Offset should be projected onto the nearest bound")
+	assert.InDelta(t, 75.0, window.project(60, cycle), 1e-9, "Offset should be projected onto the nearest (wrapped) bound")
+	assert.True(t, window.Allows(-5, cycle), "Offset within the window should be allowed")
+	assert.False(t, window.Allows(40, cycle), "Offset outside of the window should not be allowed")
+	assert.True(t, NewOffsetWindow(0, 100).Allows(40, cycle), "Window longer than the cycle should allow any offset")
+
+	discrete := NewDiscreteOffsets(60, 0, 40, 20)
+	assert.Equal(t, []float64{0, 20, 40, 60}, discrete.Offsets(), "Allowed offsets should be sorted")
+	assert.InDelta(t, 0.0, discrete.project(84, cycle), 1e-9, "Nearest allowed offset should be found around the cycle end")
+	assert.InDelta(t, 20.0, discrete.project(29, cycle), 1e-9, "Wrong nearest allowed offset")
+	assert.InDelta(t, 40.0, discrete.project(31, cycle), 1e-9, "Wrong nearest allowed offset")
+	assert.False(t, discrete.Allows(30, cycle), "Offset out of the set should not be allowed")
+
+	fixed := NewFixedOffset(90)
+	assert.InDelta(t, 5.0, fixed.project(30, cycle), 1e-9, "Fixed offset should be wrapped by the cycle")
+	assert.True(t, fixed.fixed(), "Fixed constraint should be fixed")
+	assert.True(t, NewDiscreteOffsets(30).fixed(), "Single allowed offset should be fixed")
+}
+
+func TestOptimizersOffsetConstraints(t *testing.T) {
+	newOptimizers := map[string]func(junctions []*Junction) Optimizer{
+		"genetic": func(junctions []*Junction) Optimizer {
+			return NewOptimizerGenetic(junctions, 40, 20, 20, 0.1, 3, CROSSOVER_BLEND, WithSeed(42))
+		},
+		"genetic_uniform": func(junctions []*Junction) Optimizer {
+			return NewOptimizerGenetic(junctions, 40, 20, 20, 0.1, 3, CROSSOVER_UNIFORM, WithSeed(42), WithOutputResolution(0.5))
+		},
+		"annealing": func(junctions []*Junction) Optimizer {
+			return NewOptimizerAnnealing(junctions, 40, 10, COOLING_GEOMETRIC, 0.9, 20, 10, 1, WithSeed(42))
+		},
+		"pso": func(junctions []*Junction) Optimizer {
+			return NewOptimizerPSO(junctions, 40, 10, 20, 0.7, 1.5, 1.5, SWARM_TOPOLOGY_RING, WithSeed(42))
+		},
+		"nsga2": func(junctions []*Junction) Optimizer {
+			return NewOptimizerNSGA2(junctions, 40, 20, 10, 0.1, WithSeed(42))
+		},
+		"dp": func(junctions []*Junction) Optimizer {
+			return NewOptimizerDP(junctions, 40, 1.0, true)
+		},
+		"maxband": func(junctions []*Junction) Optimizer {
+			return NewOptimizerMaxband(junctions, 40)
+		},
+		"maxband_two_way": func(junctions []*Junction) Optimizer {
+			return NewOptimizerMaxband(junctions, 40, WithTwoWay(40, 1, 1))
+		},
+	}
+	for name, newOptimizer := range newOptimizers {
+		// Constraints are in absolute time, so the first junction is not the reference one anymore
+		junctions := basicTestJuntions()
+		WithOffsetConstraint(NewFixedOffset(10))(junctions[1])
+		WithOffsetConstraint(NewOffsetWindow(60, 70))(junctions[2])
+		WithOffsetConstraint(NewDiscreteOffsets(0, 20, 40, 60))(junctions[3])
+		bestOffsets := newOptimizer(junctions).Optimize()
+		assert.GreaterOrEqual(t, bestOffsets[0], 0.0, "Optimizer %s: offset of the first junction should be within its cycle", name)
+		assert.Less(t, bestOffsets[0], junctions[0].GetTotalDuration(), "Optimizer %s: offset of the first junction should be within its cycle", name)
+		for i := 1; i < len(junctions); i++ {
+			assert.True(t, junctions[i].GetOffsetConstraint().Allows(bestOffsets[i], junctions[i].GetTotalDuration()), "Optimizer %s: offset %v of junction %d is not allowed", name, bestOffsets[i], i)
+		}
+
+		// Constrained first junction is not pinned at zero
+		junctions = basicTestJuntions()
+		WithOffsetConstraint(NewFixedOffset(12))(junctions[0])
+		WithOffsetConstraint(NewOffsetWindow(-5, 5))(junctions[3])
+		bestOffsets = newOptimizer(junctions).Optimize()
+		assert.InDelta(t, 12.0, bestOffsets[0], 1e-9, "Optimizer %s: fixed offset of the first junction should be kept", name)
+		assert.True(t, junctions[3].GetOffsetConstraint().Allows(bestOffsets[3], junctions[3].GetTotalDuration()), "Optimizer %s: offset %v of the last junction is not allowed", name, bestOffsets[3])
+	}
+}
+
+// freeReferenceTestJunctions returns junctions with 90 seconds cycle and 30 seconds green, travel time between neighbours is 15 seconds at 36 km/h
+func freeReferenceTestJunctions() []*Junction {
+	junctions := make([]*Junction, 3)
+	for i := range junctions {
+		junctions[i] = NewJunction(
+			[]*Phase{
+				NewPhase(i, []*Signal{
+					NewSignal(30, color.GREEN),
+					NewSignal(60, color.RED),
+				}),
+			},
+			WithPoint(Point{X: 0, Y: 150 * float64(i)}),
+		)
+	}
+	return junctions
+}
+
+func TestOffsetConstraintsFreeReference(t *testing.T) {
+	// Fixed offset of the middle junction is absolute: the first junction has to follow it to keep the full band
+	newOptimizers := map[string]func(junctions []*Junction) Optimizer{
+		"genetic": func(junctions []*Junction) Optimizer {
+			return NewOptimizerGenetic(junctions, 36, 30, 50, 0.2, 3, CROSSOVER_BLEND, WithFitness(MaxBandFitness{}), WithSeed(42))
+		},
+		"annealing": func(junctions []*Junction) Optimizer {
+			return NewOptimizerAnnealing(junctions, 36, 10, COOLING_GEOMETRIC, 0.9, 50, 20, 0, WithFitness(MaxBandFitness{}), WithSeed(42))
+		},
+		"pso": func(junctions []*Junction) Optimizer {
+			return NewOptimizerPSO(junctions, 36, 20, 50, 0.7, 1.5, 1.5, SWARM_TOPOLOGY_GLOBAL, WithFitness(MaxBandFitness{}), WithSeed(42))
+		},
+		"nsga2": func(junctions []*Junction) Optimizer {
+			return NewOptimizerNSGA2(junctions, 36, 30, 50, 0.2, WithFitness(MaxBandFitness{}), WithSeed(42))
+		},
+		"dp": func(junctions []*Junction) Optimizer {
+			return NewOptimizerDP(junctions, 36, 1.0, false, WithFitness(MaxBandFitness{}))
+		},
+		"maxband": func(junctions []*Junction) Optimizer {
+			return NewOptimizerMaxband(junctions, 36, WithFitness(MaxBandFitness{}))
+		},
+	}
+	for name, newOptimizer := range newOptimizers {
+		junctions := freeReferenceTestJunctions()
+		WithOffsetConstraint(NewFixedOffset(20))(junctions[1])
+		optimizer := newOptimizer(junctions)
+		bestOffsets := optimizer.Optimize()
+		bestFitness := optimizer.(interface{ BestFitness() float64 }).BestFitness()
+		assert.InDelta(t, 20.0, bestOffsets[1], 1e-9, "Optimizer %s: fixed offset should be kept", name)
+		// Band is 25 seconds at most if the first junction is pinned at zero
+		assert.Greater(t, bestFitness, 29.0, "Optimizer %s: the first junction should be shifted along with the fixed one", name)
+		if name == "dp" || name == "maxband" {
+			assert.InDeltaSlice(t, []float64{5, 20, 35}, bestOffsets, 1e-9, "Optimizer %s: wrong optimal offsets", name)
+			assert.InDelta(t, 30.0, bestFitness, 1e-9, "Optimizer %s: full band should be found", name)
+		}
+	}
+}
@@ -1,7 +1,220 @@
 package greenwave
 
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
 // Optimizer is an interface that defines a method for optimizing offsets for traffic lights signal timing.
 type Optimizer interface {
 	// Optimize calculates the optimal offsets for traffic lights and returns them as a slice of float64.
 	Optimize() []float64
 }
+
+// OptimizerSettings contains settings which are common for every optimizer implementation.
+type OptimizerSettings struct {
+	// twoWay enables optimization of both outbound and inbound directions
+	twoWay bool
+	// inboundSpeedKmh is the speed in kilometers per hour for the inbound direction
+	inboundSpeedKmh float64
+	// outboundWeight is the weight of the outbound fitness in the combined objective
+	outboundWeight float64
+	// inboundWeight is the weight of the inbound fitness in the combined objective
+	inboundWeight float64
+	// extractOptions are passed to green waves extraction during fitness evaluation
+	extractOptions []func(*ExtractSettings)
+	// fitness evaluates green waves of each direction
+	fitness FitnessFunc
+	// outputResolution is the resolution in seconds of the resulting offsets. Zero means no rounding
+	outputResolution float64
+	// greenSplits enables optimization of signal durations within their bounds alongside offsets
+	greenSplits bool
+	// greenSplitMode defines whether the cycle length is kept during green split optimization
+	greenSplitMode GreenSplitMode
+	// workers is the number of goroutines evaluating the population concurrently. Values less than 2 mean sequential evaluation
+	workers int
+	// timeBudget limits wall-clock time of the context-aware optimization. Zero means no limit
+	timeBudget time.Duration
+	// maxStagnantGenerations stops the context-aware optimization after the given number of generations without improvement. Zero means no limit
+	maxStagnantGenerations int
+	// targetFitness stops the context-aware optimization when reached. Nil means no target
+	targetFitness *float64
+	// observers receive progress of the optimization after each generation
+	observers []Observer
+	// seed is the seed of the random source. Zero if custom random source is provided via WithRandSource
+	seed uint64
+	// random is the source of randomness of stochastic optimizers
+	random *rand.Rand
+}
+
+// newOptimizerSettings creates settings with defaults (outbound direction only) and applies provided options.
+func newOptimizerSettings(options ...func(*OptimizerSettings)) *OptimizerSettings {
+	settings := &OptimizerSettings{
+		twoWay:         false,
+		outboundWeight: 1.0,
+		inboundWeight:  1.0,
+		fitness:        DepthWeightedBandwidthFitness{},
+	}
+	for _, option := range options {
+		option(settings)
+	}
+	if settings.random == nil {
+		// Random seed if neither seed nor random source is provided
+		settings.seed = rand.Uint64()
+		settings.random = newSeededRand(settings.seed)
+	}
+	return settings
+}
+
+// WithTwoWay is an option function that enables two-way (outbound + inbound) optimization.
+// Combined fitness is outboundWeight * outboundFitness + inboundWeight * inboundFitness.
+func WithTwoWay(inboundSpeedKmh, outboundWeight, inboundWeight float64) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.twoWay = true
+		s.inboundSpeedKmh = inboundSpeedKmh
+		s.outboundWeight = outboundWeight
+		s.inboundWeight = inboundWeight
+	}
+}
+
+// WithWorkers is an option function that sets the number of goroutines evaluating the population of the genetic algorithm concurrently.
+// Evaluation does not modify junctions and does not consume random numbers, so the result does not depend on the number of workers.
+func WithWorkers(workers int) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.workers = workers
+	}
+}
+
+// WithSeed is an option function that sets the seed of the random source of stochastic optimizers.
+// Optimizers created with the same seed and input produce the same result.
+func WithSeed(seed uint64) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.seed = seed
+		s.random = newSeededRand(seed)
+	}
+}
+
+// WithRandSource is an option function that sets custom random source of stochastic optimizers.
+func WithRandSource(source rand.Source) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.seed = 0
+		s.random = rand.New(source)
+	}
+}
+
+// WithExtractOptions is an option function that sets green waves extraction options (e.g. WithSegments) used during fitness evaluation.
+func WithExtractOptions(options ...func(*ExtractSettings)) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.extractOptions = append(s.extractOptions, options...)
+	}
+}
+
+// WithOutputResolution is an option function that sets the resolution (in seconds) of the resulting offsets.
+// E.g. 0.1 for controllers working in tenths of a second. The final plan is rounded and re-evaluated,
+// so the reported fitness matches the offsets which are going to be deployed.
+func WithOutputResolution(seconds float64) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.outputResolution = seconds
+	}
+}
+
+// WithGreenSplits is an option function that enables green split optimization: durations of signals within their
+// MinDuration/MaxDuration bounds become decision variables alongside offsets (see GreenSplitMode).
+// Signals without bounds (MinDuration == MaxDuration == Duration) keep their durations.
+func WithGreenSplits(mode GreenSplitMode) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.greenSplits = true
+		s.greenSplitMode = mode
+	}
+}
+
+// RoundOffsets rounds offsets to the given resolution in seconds keeping each offset within [0; cycle) of its junction.
+// Non-positive resolution leaves offsets as is. Returns new slice.
+func RoundOffsets(offsets []float64, cycleLengths []float64, resolution float64) []float64 {
+	rounded := make([]float64, len(offsets))
+	copy(rounded, offsets)
+	if resolution <= 0 {
+		return rounded
+	}
+	for i, offset := range offsets {
+		offset = roundToResolution(offset, resolution)
+		if i < len(cycleLengths) && cycleLengths[i] > 0 {
+			offset = wrapTime(offset, cycleLengths[i])
+		}
+		rounded[i] = offset
+	}
+	return rounded
+}
+
+// roundToResolution rounds the value to the given resolution getting rid of floating point noise (e.g. 78.50000000000001).
+func roundToResolution(value, resolution float64) float64 {
+	value = math.Round(value/resolution) * resolution
+	return math.Round(value*1e9) / 1e9
+}
+
+// corridorFitness calculates fitness (see WithFitness) of the current junctions offsets.
+// In two-way mode outbound and inbound fitness values are combined using configured weights.
+func corridorFitness(junctions []*Junction, outboundSpeedKmh float64, settings *OptimizerSettings) float64 {
+	extractSettings := newExtractSettings(settings.extractOptions...)
+	outboundFitness := settings.fitness.Evaluate(newCorridorWaves(junctions, outboundSpeedKmh, extractSettings, false))
+	if !settings.twoWay {
+		return outboundFitness
+	}
+	inboundFitness := settings.fitness.Evaluate(newCorridorWaves(junctions, settings.inboundSpeedKmh, extractSettings, true))
+	return settings.outboundWeight*outboundFitness + settings.inboundWeight*inboundFitness
+}
+
+// EvaluateOffsets calculates the corridor fitness (the same as stochastic optimizers maximize) of the given offsets without modifying junctions.
+// Signal durations of each junction in cycle order (see Junction.GetSignalDurations) are optional: nil means current durations.
+// It is safe to call it concurrently for the same junctions.
+func EvaluateOffsets(junctions []*Junction, speedKmh float64, offsets []float64, durations [][]float64, options ...func(*OptimizerSettings)) float64 {
+	return evaluateOffsets(junctions, speedKmh, offsets, durations, newOptimizerSettings(options...))
+}
+
+// evaluateOffsets calculates the corridor fitness of the plan without modifying junctions.
+func evaluateOffsets(junctions []*Junction, speedKmh float64, offsets []float64, durations [][]float64, settings *OptimizerSettings) float64 {
+	return corridorFitness(junctionsWithPlan(junctions, offsets, durations), speedKmh, settings)
+}
+
+// junctionsWithPlan returns copies of junctions with the given offsets (and signal durations) applied.
+// Copies are shallow (phases and signals are shared) unless signal durations are provided.
+func junctionsWithPlan(junctions []*Junction, offsets []float64, durations [][]float64) []*Junction {
+	planned := make([]*Junction, len(junctions))
+	for i, junction := range junctions {
+		if durations != nil {
+			planned[i] = junction.clone()
+			planned[i].SetSignalDurations(durations[i])
+		} else {
+			shallow := *junction
+			planned[i] = &shallow
+		}
+		planned[i].offset = offsets[i]
+	}
+	return planned
+}
+
+// throughWavesFitness calculates fitness based on the depth and band size of the through green waves.
+func throughWavesFitness(throughGreenWaves []*ThroughGreenWave, maxDepth int) float64 {
+	if len(throughGreenWaves) == 0 {
+		return 0.0 // No green waves found
+	}
+	totalFitness := 0.0
+	for _, wave := range throughGreenWaves {
+		depthRatio := float64(wave.Depth()) / float64(maxDepth)
+		// Square the depth ratio to emphasize deeper wave
+		waveFitness := depthRatio * depthRatio * float64(wave.Bandwidth())
+		totalFitness += waveFitness
+	}
+	return totalFitness
+}
+
+// newSeededRand creates the random generator (PCG) with the given seed.
+func newSeededRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+// randomFloat generates a random float64 between min and max
+func randomFloat(random *rand.Rand, min, max float64) float64 {
+	return min + (max-min)*random.Float64()
+}
@@ -0,0 +1,204 @@
+package greenwave
+
+import (
+	"context"
+	"math"
+)
+
+// CoolingSchedule defines how the temperature decreases in simulated annealing
+type CoolingSchedule uint8
+
+const (
+	// COOLING_GEOMETRIC multiplies the temperature by the cooling rate after each temperature step
+	COOLING_GEOMETRIC CoolingSchedule = iota
+	// COOLING_LINEAR decreases the temperature linearly from the initial one to zero over the temperature steps
+	COOLING_LINEAR
+	// COOLING_ADAPTIVE is the geometric cooling which cools faster when most moves are accepted and slower when most moves are rejected
+	COOLING_ADAPTIVE
+)
+
+var coolingScheduleToStr = [...]string{"geometric", "linear", "adaptive"}
+
+// String returns the string representation of the CoolingSchedule
+func (schedule CoolingSchedule) String() string {
+	return coolingScheduleToStr[schedule]
+}
+
+// OptimizerAnnealing is a simulated annealing optimizer for traffic light offsets.
+// Each move perturbs the offset of a single junction modulo its cycle, perturbation range shrinks along with the temperature.
+type OptimizerAnnealing struct {
+	// contains the traffic junctions to optimize
+	junctions []*Junction
+	// speedKhm is the speed in kilometers per hour used for calculating offsets
+	speedKhm float64
+	// initialTemperature is the temperature at the beginning of each run
+	initialTemperature float64
+	// coolingSchedule defines how the temperature decreases
+	coolingSchedule CoolingSchedule
+	// coolingRate is the multiplier of the temperature for geometric and adaptive cooling
+	coolingRate float64
+	// temperatureSteps is the number of temperature steps of each run
+	temperatureSteps int
+	// iterationsPerTemperature is the number of moves at each temperature
+	iterationsPerTemperature int
+	// restarts is the number of additional runs from random offsets
+	restarts int
+	// cycleLengths contains the total duration of each junction in seconds
+	cycleLengths []float64
+	// space contains allowed offsets of each junction (see OffsetConstraint)
+	space *offsetSpace
+	// bestFitenessHistory keeps track of the best fitness value after each temperature step (runs are concatenated)
+	bestFitenessHistory []float64
+	// bestFitness is the fitness of the resulting offsets (after rounding to the output resolution)
+	bestFitness float64
+	// settings contains common optimizer settings (e.g. two-way optimization)
+	settings *OptimizerSettings
+}
+
+// NewOptimizerAnnealing creates a new instance of OptimizerAnnealing with the provided parameters
+// Optional settings (e.g. WithTwoWay) could be provided via options.
+func NewOptimizerAnnealing(junctions []*Junction, speedKhm float64, initialTemperature float64, coolingSchedule CoolingSchedule, coolingRate float64, temperatureSteps int, iterationsPerTemperature int, restarts int, options ...func(*OptimizerSettings)) Optimizer {
+	cycleLengths := junctionCycles(junctions)
+	return &OptimizerAnnealing{
+		junctions:                junctions,
+		speedKhm:                 speedKhm,
+		initialTemperature:       initialTemperature,
+		coolingSchedule:          coolingSchedule,
+		coolingRate:              coolingRate,
+		temperatureSteps:         temperatureSteps,
+		iterationsPerTemperature: iterationsPerTemperature,
+		restarts:                 restarts,
+		cycleLengths:             cycleLengths,
+		space:                    newOffsetSpace(junctions, cycleLengths),
+		bestFitenessHistory:      make([]float64, 0, (restarts+1)*temperatureSteps),
+		settings:                 newOptimizerSettings(options...),
+	}
+}
+
+// evaluate calculates the corridor fitness of offsets. Junctions are not modified.
+func (optsa *OptimizerAnnealing) evaluate(offsets []float64) float64 {
+	return evaluateOffsets(optsa.junctions, optsa.speedKhm, offsets, nil, optsa.settings)
+}
+
+// randomOffsets returns random allowed offsets within each junction cycle. The first offset is always 0.0 unless any junction is constrained
+func (optsa *OptimizerAnnealing) randomOffsets() []float64 {
+	offsets := optsa.space.initial()
+	for _, i := range optsa.space.variables {
+		offsets[i] = optsa.space.random(optsa.settings.random, i)
+	}
+	return offsets
+}
+
+// nextTemperature returns the temperature for the next step given the acceptance ratio of the current one.
+func (optsa *OptimizerAnnealing) nextTemperature(temperature float64, step int, acceptanceRatio float64) float64 {
+	switch optsa.coolingSchedule {
+	case COOLING_LINEAR:
+		return optsa.initialTemperature * (1 - float64(step+1)/float64(optsa.temperatureSteps))
+	case COOLING_ADAPTIVE:
+		switch {
+		case acceptanceRatio > 0.8:
+			return temperature * optsa.coolingRate * optsa.coolingRate
+		case acceptanceRatio < 0.2:
+			return temperature * math.Sqrt(optsa.coolingRate)
+		}
+		return temperature * optsa.coolingRate
+	default:
+		return temperature * optsa.coolingRate
+	}
+}
+
+// Optimize runs simulated annealing (with restarts) to calculate the optimal offsets for the traffic lights
+func (optsa *OptimizerAnnealing) Optimize() []float64 {
+	return optsa.OptimizeContext(context.Background()).Offsets
+}
+
+// OptimizeContext runs simulated annealing until every temperature step of every run is done, context is cancelled or early stopping criteria is met.
+// Stagnation is counted in temperature steps. Returns the best offsets found so far
+func (optsa *OptimizerAnnealing) OptimizeContext(ctx context.Context) *OptimizeResult {
+	criteria, cancel := optsa.settings.newStopCriteria(ctx)
+	defer cancel()
+	result := &OptimizeResult{StopReason: STOP_COMPLETED}
+	optsa.bestFitenessHistory = optsa.bestFitenessHistory[:0]
+	bestOffsets := optsa.space.initial()
+	bestFitness := math.Inf(-1)
+	if len(optsa.space.variables) == 0 {
+		optsa.bestFitness = optsa.evaluate(bestOffsets)
+		result.Offsets, result.Fitness = bestOffsets, optsa.bestFitness
+		return result
+	}
+
+runs:
+	for run := 0; run <= optsa.restarts; run++ {
+		current := optsa.randomOffsets()
+		currentFitness := optsa.evaluate(current)
+		if currentFitness > bestFitness {
+			bestFitness = currentFitness
+			copy(bestOffsets, current)
+		}
+		temperature := optsa.initialTemperature
+		for step := 0; step < optsa.temperatureSteps; step++ {
+			// Perturbation range shrinks along with the temperature
+			temperatureRatio := 0.0
+			if optsa.initialTemperature > 0 {
+				temperatureRatio = math.Max(0, temperature/optsa.initialTemperature)
+			}
+			accepted := 0
+			for iteration := 0; iteration < optsa.iterationsPerTemperature; iteration++ {
+				i := optsa.space.variables[optsa.settings.random.IntN(len(optsa.space.variables))]
+				maxDelta := math.Max(0.5, 0.25*optsa.cycleLengths[i]*temperatureRatio)
+				previousOffset := current[i]
+				current[i] = optsa.space.perturb(optsa.settings.random, i, current[i], maxDelta)
+				candidateFitness := optsa.evaluate(current)
+				// Metropolis criterion (maximization)
+				if candidateFitness >= currentFitness || (temperature > 0 && optsa.settings.random.Float64() < math.Exp((candidateFitness-currentFitness)/temperature)) {
+					currentFitness = candidateFitness
+					accepted++
+					if currentFitness > bestFitness {
+						bestFitness = currentFitness
+						copy(bestOffsets, current)
+					}
+				} else {
+					current[i] = previousOffset
+				}
+			}
+			acceptanceRatio := 0.0
+			if optsa.iterationsPerTemperature > 0 {
+				acceptanceRatio = float64(accepted) / float64(optsa.iterationsPerTemperature)
+			}
+			temperature = optsa.nextTemperature(temperature, step, acceptanceRatio)
+			optsa.bestFitenessHistory = append(optsa.bestFitenessHistory, bestFitness)
+			result.Iterations++
+			if optsa.settings.observed() {
+				// Single current state: mean and worst fitness are its fitness, diversity is zero
+				progress := populationProgress(result.Iterations-1, bestFitness, bestOffsets, []float64{currentFitness}, [][]float64{current}, optsa.cycleLengths)
+				if !optsa.settings.notify(progress) {
+					result.StopReason = STOP_ABORTED
+					break runs
+				}
+			}
+			if reason, stop := criteria.check(bestFitness); stop {
+				result.StopReason = reason
+				break runs
+			}
+		}
+	}
+
+	if optsa.settings.outputResolution > 0 {
+		// Round the final plan and re-evaluate it, so reported fitness matches deployed offsets
+		bestOffsets = optsa.space.round(bestOffsets, optsa.settings.outputResolution)
+	}
+	optsa.bestFitness = optsa.evaluate(bestOffsets)
+	result.Offsets, result.Fitness = bestOffsets, optsa.bestFitness
+	return result
+}
+
+// BestFitnessHistory returns the history of the best fitness values after each temperature step (runs are concatenated)
+// Returns slice, do not modify it
+func (optsa *OptimizerAnnealing) BestFitnessHistory() []float64 {
+	return optsa.bestFitenessHistory
+}
+
+// BestFitness returns the fitness of the offsets returned by the last Optimize call
+func (optsa *OptimizerAnnealing) BestFitness() float64 {
+	return optsa.bestFitness
+}
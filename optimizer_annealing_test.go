@@ -0,0 +1,29 @@
+package greenwave
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizerAnnealing(t *testing.T) {
+	junctions := basicTestJuntions()
+	temperatureSteps, restarts := 30, 2
+	for _, schedule := range []CoolingSchedule{COOLING_GEOMETRIC, COOLING_LINEAR, COOLING_ADAPTIVE} {
+		optimizer := NewOptimizerAnnealing(junctions, 40, 10, schedule, 0.9, temperatureSteps, 20, restarts).(*OptimizerAnnealing)
+		offsets := optimizer.Optimize()
+		assert.Len(t, offsets, len(junctions), "Schedule %s: wrong number of offsets", schedule)
+		assert.Equal(t, 0.0, offsets[0], "Schedule %s: first offset should be zero", schedule)
+		for i, offset := range offsets {
+			assert.GreaterOrEqual(t, offset, 0.0, "Schedule %s: offset %d out of cycle", schedule, i)
+			assert.Less(t, offset, junctions[i].GetTotalDuration(), "Schedule %s: offset %d out of cycle", schedule, i)
+		}
+
+		history := optimizer.BestFitnessHistory()
+		assert.Len(t, history, (restarts+1)*temperatureSteps, "Schedule %s: wrong history length", schedule)
+		for i := 1; i < len(history); i++ {
+			assert.GreaterOrEqual(t, history[i], history[i-1], "Schedule %s: best fitness should not decrease", schedule)
+		}
+		assert.InDelta(t, history[len(history)-1], optimizer.BestFitness(), 1e-9, "Schedule %s: best fitness mismatch", schedule)
+	}
+}
@@ -0,0 +1,135 @@
+package greenwave
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// StopReason defines why the optimization has been stopped
+type StopReason uint8
+
+const (
+	// STOP_COMPLETED means that every generation (iteration) has been run
+	STOP_COMPLETED StopReason = iota
+	// STOP_CANCELLED means that the context has been cancelled
+	STOP_CANCELLED
+	// STOP_DEADLINE means that the time budget has been exhausted or the context deadline has been exceeded
+	STOP_DEADLINE
+	// STOP_STAGNATION means that the best fitness has not been improved for the max number of stagnant generations
+	STOP_STAGNATION
+	// STOP_TARGET_FITNESS means that the target fitness has been reached
+	STOP_TARGET_FITNESS
+	// STOP_ABORTED means that an observer has aborted the optimization (see Observer)
+	STOP_ABORTED
+)
+
+var stopReasonToStr = [...]string{"completed", "cancelled", "deadline", "stagnation", "target_fitness", "aborted"}
+
+// String returns the string representation of the StopReason
+func (reason StopReason) String() string {
+	return stopReasonToStr[reason]
+}
+
+// OptimizeResult is the result of the context-aware optimization
+type OptimizeResult struct {
+	// Offsets contains the best offsets found so far
+	Offsets []float64
+	// Fitness is the fitness of the offsets (after rounding to the output resolution)
+	Fitness float64
+	// StopReason defines why the optimization has been stopped
+	StopReason StopReason
+	// Iterations is the number of completed generations (iterations, temperature steps)
+	Iterations int
+}
+
+// ContextOptimizer is an optimizer which could be cancelled via context and stopped early (see WithTimeBudget, WithMaxStagnantGenerations and WithTargetFitness).
+// Best offsets found so far are returned whenever the optimization stops.
+// It is implemented by stochastic optimizers (genetic, simulated annealing, particle swarm and NSGA-II), deterministic ones run to completion.
+type ContextOptimizer interface {
+	Optimizer
+	// OptimizeContext calculates the optimal offsets for traffic lights until completion, cancellation or early stopping.
+	OptimizeContext(ctx context.Context) *OptimizeResult
+}
+
+// WithTimeBudget is an option function that limits wall-clock time of the context-aware optimization.
+// Zero (default) means no limit.
+func WithTimeBudget(budget time.Duration) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.timeBudget = budget
+	}
+}
+
+// WithMaxStagnantGenerations is an option function that stops the context-aware optimization when the best fitness
+// has not been improved for the given number of consecutive generations (iterations, temperature steps). Zero (default) means no limit.
+func WithMaxStagnantGenerations(generations int) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.maxStagnantGenerations = generations
+	}
+}
+
+// WithTargetFitness is an option function that stops the context-aware optimization as soon as the best fitness reaches the target.
+func WithTargetFitness(fitness float64) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.targetFitness = &fitness
+	}
+}
+
+// stopCriteria checks stop conditions of the optimization after each generation
+type stopCriteria struct {
+	ctx context.Context
+	// maxStagnant is the max number of consecutive generations without improvement. Zero means no limit
+	maxStagnant int
+	// targetFitness is the fitness to reach. Nil means no target
+	targetFitness *float64
+	// stagnant is the current number of consecutive generations without improvement
+	stagnant int
+	// bestFitness is the best fitness seen so far
+	bestFitness float64
+	// started is true after the first check
+	started bool
+}
+
+// newStopCriteria creates stop criteria from the settings. Returned context is limited by the time budget and must be released by the cancel function.
+func (s *OptimizerSettings) newStopCriteria(ctx context.Context) (*stopCriteria, context.CancelFunc) {
+	cancel := context.CancelFunc(func() {})
+	if s.timeBudget > 0 {
+		ctx, cancel = context.WithTimeout(ctx, s.timeBudget)
+	}
+	return &stopCriteria{
+		ctx:           ctx,
+		maxStagnant:   s.maxStagnantGenerations,
+		targetFitness: s.targetFitness,
+	}, cancel
+}
+
+// interrupted returns the stop reason if the context is done.
+func (criteria *stopCriteria) interrupted() (StopReason, bool) {
+	err := criteria.ctx.Err()
+	switch {
+	case err == nil:
+		return STOP_COMPLETED, false
+	case errors.Is(err, context.DeadlineExceeded):
+		return STOP_DEADLINE, true
+	default:
+		return STOP_CANCELLED, true
+	}
+}
+
+// check registers the best fitness after a generation and returns the stop reason if optimization should be stopped.
+func (criteria *stopCriteria) check(bestFitness float64) (StopReason, bool) {
+	if !criteria.started || bestFitness > criteria.bestFitness+eps {
+		criteria.started = true
+		criteria.bestFitness = bestFitness
+		criteria.stagnant = 0
+	} else {
+		criteria.stagnant++
+	}
+	if criteria.targetFitness != nil && bestFitness >= *criteria.targetFitness {
+		return STOP_TARGET_FITNESS, true
+	}
+	if criteria.maxStagnant > 0 && criteria.stagnant >= criteria.maxStagnant {
+		return STOP_STAGNATION, true
+	}
+	return criteria.interrupted()
+}
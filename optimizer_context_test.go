@@ -0,0 +1,60 @@
+package greenwave
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizeContext(t *testing.T) {
+	junctions := basicTestJuntions()
+	newOptimizers := map[string]func(options ...func(*OptimizerSettings)) ContextOptimizer{
+		"genetic": func(options ...func(*OptimizerSettings)) ContextOptimizer {
+			return NewOptimizerGenetic(junctions, 40, 20, 300, 0.1, 3, CROSSOVER_BLEND, options...).(ContextOptimizer)
+		},
+		"annealing": func(options ...func(*OptimizerSettings)) ContextOptimizer {
+			return NewOptimizerAnnealing(junctions, 40, 10, COOLING_GEOMETRIC, 0.95, 300, 10, 0, options...).(ContextOptimizer)
+		},
+		"pso": func(options ...func(*OptimizerSettings)) ContextOptimizer {
+			return NewOptimizerPSO(junctions, 40, 10, 300, 0.7, 1.5, 1.5, SWARM_TOPOLOGY_GLOBAL, options...).(ContextOptimizer)
+		},
+		"nsga2": func(options ...func(*OptimizerSettings)) ContextOptimizer {
+			return NewOptimizerNSGA2(junctions, 40, 10, 300, 0.1, options...).(ContextOptimizer)
+		},
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, newOptimizer := range newOptimizers {
+		result := newOptimizer(WithSeed(1)).OptimizeContext(cancelled)
+		assert.Equal(t, STOP_CANCELLED, result.StopReason, "Optimizer %s: wrong stop reason", name)
+		assert.Equal(t, 1, result.Iterations, "Optimizer %s: single generation should be done before cancellation is checked", name)
+		assert.Len(t, result.Offsets, len(junctions), "Optimizer %s: best-so-far offsets expected", name)
+
+		result = newOptimizer(WithSeed(1), WithTimeBudget(time.Nanosecond)).OptimizeContext(context.Background())
+		assert.Equal(t, STOP_DEADLINE, result.StopReason, "Optimizer %s: wrong stop reason", name)
+		assert.Len(t, result.Offsets, len(junctions), "Optimizer %s: best-so-far offsets expected", name)
+
+		result = newOptimizer(WithSeed(1)).OptimizeContext(context.Background())
+		assert.Equal(t, STOP_COMPLETED, result.StopReason, "Optimizer %s: wrong stop reason", name)
+		assert.Equal(t, 300, result.Iterations, "Optimizer %s: every generation should be done", name)
+	}
+
+	// Early stopping criteria
+	optimizer := NewOptimizerGenetic(junctions, 40, 20, 300, 0.1, 3, CROSSOVER_BLEND, WithSeed(1), WithTargetFitness(0)).(*OptimizerGenetic)
+	result := optimizer.OptimizeContext(context.Background())
+	assert.Equal(t, STOP_TARGET_FITNESS, result.StopReason, "Wrong stop reason")
+	assert.Equal(t, 1, result.Iterations, "Target fitness should be reached in the first generation")
+
+	stagnant := 5
+	optimizer = NewOptimizerGenetic(junctions, 40, 20, 300, 0.1, 3, CROSSOVER_BLEND, WithSeed(1), WithMaxStagnantGenerations(stagnant)).(*OptimizerGenetic)
+	result = optimizer.OptimizeContext(context.Background())
+	assert.Equal(t, STOP_STAGNATION, result.StopReason, "Wrong stop reason")
+	history := optimizer.BestFitnessHistory()
+	assert.Len(t, history, result.Iterations, "History should contain every completed generation")
+	for i := len(history) - stagnant; i < len(history); i++ {
+		assert.InDelta(t, history[len(history)-stagnant-1], history[i], eps, "Best fitness should not be improved within stagnant generations")
+	}
+	assert.InDelta(t, history[len(history)-1], result.Fitness, 1e-9, "Best-so-far fitness mismatch")
+}
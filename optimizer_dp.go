@@ -0,0 +1,212 @@
+package greenwave
+
+import (
+	"math"
+	"slices"
+)
+
+// dpMaxCorrectionPasses is the maximum number of chain-level correction passes over all junctions
+const dpMaxCorrectionPasses = 10
+
+// OptimizerDP is a deterministic offsets optimizer based on dynamic programming over discretized offsets.
+// Since the corridor is a chain, bandwidth between neighbouring junctions depends on their offsets only, so the sum of pairwise
+// bandwidths (both directions in two-way mode) is maximized exactly over the offsets grid in O(n * K^2) pair evaluations,
+// where K is the number of offsets candidates per junction. Optional chain-level correction then refines the plan junction by junction
+// using the corridor fitness (through waves), which pairwise bandwidth does not capture. Correction is always applied if the fitness
+// other than the default one is selected (see WithFitness), since the pairwise bandwidth is a proxy of the default fitness only.
+type OptimizerDP struct {
+	// contains the traffic junctions to optimize
+	junctions []*Junction
+	// speedKhm is the speed in kilometers per hour used for calculating offsets
+	speedKhm float64
+	// step is the offsets discretization step in seconds
+	step float64
+	// chainCorrection enables chain-level correction of the pairwise optimal plan
+	chainCorrection bool
+	// space contains allowed offsets of each junction (see OffsetConstraint)
+	space *offsetSpace
+	// settings contains common optimizer settings (e.g. two-way optimization)
+	settings *OptimizerSettings
+	// pairwiseScore is the weighted sum of pairwise bandwidths of the pairwise optimal plan
+	pairwiseScore float64
+	// bestFitness is the fitness of the resulting offsets (after rounding to the output resolution)
+	bestFitness float64
+}
+
+// NewOptimizerDP creates a new instance of OptimizerDP with the provided offsets step (in seconds) and chain-level correction flag.
+// Optional settings (e.g. WithTwoWay) could be provided via options.
+func NewOptimizerDP(junctions []*Junction, speedKhm float64, step float64, chainCorrection bool, options ...func(*OptimizerSettings)) Optimizer {
+	return &OptimizerDP{
+		junctions:       junctions,
+		speedKhm:        speedKhm,
+		step:            step,
+		chainCorrection: chainCorrection,
+		space:           newOffsetSpace(junctions, junctionCycles(junctions)),
+		settings:        newOptimizerSettings(options...),
+	}
+}
+
+// candidates returns discretized offsets of the junction: 0, step, 2*step, ... within the junction cycle.
+// Only allowed offsets are returned for constrained junctions: grid offsets within the window (or the window start if the window is narrower than the step),
+// allowed values of the discrete set or the fixed offset.
+func (optdp *OptimizerDP) candidates(junctionIdx int) []float64 {
+	cycle := optdp.junctions[junctionIdx].totalDuration
+	constraint := optdp.space.constraints[junctionIdx]
+	if constraint.fixed() || cycle <= 0 || optdp.step <= 0 {
+		return []float64{optdp.space.project(junctionIdx, 0)} // The first offset is always 0.0 unless any junction is constrained
+	}
+	if constraint != nil && constraint.constraintType == OFFSET_CONSTRAINT_DISCRETE {
+		offsets := make([]float64, 0, len(constraint.offsets))
+		for _, offset := range constraint.offsets {
+			if offset = wrapTime(offset, cycle); !slices.Contains(offsets, offset) {
+				offsets = append(offsets, offset)
+			}
+		}
+		return offsets
+	}
+	candidatesNum := int(math.Ceil(cycle/optdp.step - eps))
+	offsets := make([]float64, 0, candidatesNum)
+	for k := 0; k < candidatesNum; k++ {
+		if offset := float64(k) * optdp.step; constraint == nil || constraint.Allows(offset, cycle) {
+			offsets = append(offsets, offset)
+		}
+	}
+	if len(offsets) == 0 {
+		return []float64{wrapTime(constraint.min, cycle)}
+	}
+	return offsets
+}
+
+// Optimize runs the dynamic programming over discretized offsets and returns the optimal offsets.
+func (optdp *OptimizerDP) Optimize() []float64 {
+	junctionsNum := len(optdp.junctions)
+	offsets := make([]float64, junctionsNum)
+	if junctionsNum == 0 {
+		return offsets
+	}
+	extractSettings := newExtractSettings(optdp.settings.extractOptions...)
+	hyperperiod := extractSettings.hyperperiod(optdp.junctions)
+
+	// Green intervals of each junction for every offset candidate (per direction)
+	candidates := make([][]float64, junctionsNum)
+	outboundIntervals := make([][][]*GreenInterval, junctionsNum)
+	inboundIntervals := make([][][]*GreenInterval, junctionsNum)
+	for j, junction := range optdp.junctions {
+		candidates[j] = optdp.candidates(j)
+		outboundIntervals[j] = make([][]*GreenInterval, len(candidates[j]))
+		inboundIntervals[j] = make([][]*GreenInterval, len(candidates[j]))
+		originalOffset := junction.GetOffset()
+		for k, offset := range candidates[j] {
+			junction.SetOffset(offset)
+			outboundIntervals[j][k] = adjustIntervalsByOffset(junction, extractSettings.effectiveGreen, extractSettings.signalGroup(j, false), hyperperiod)
+			if optdp.settings.twoWay {
+				inboundIntervals[j][k] = adjustIntervalsByOffset(junction, extractSettings.effectiveGreen, extractSettings.signalGroup(j, true), hyperperiod)
+			}
+		}
+		junction.SetOffset(originalOffset)
+	}
+
+	// score[j][k] is the best sum of pairwise bandwidths of junctions [0; j] with k-th offset candidate of junction j
+	score := make([][]float64, junctionsNum)
+	previous := make([][]int, junctionsNum)
+	score[0] = make([]float64, len(candidates[0]))
+	for j := 1; j < junctionsNum; j++ {
+		segment := extractSettings.segment(j - 1)
+		distanceMeters := segment.Distance(optdp.junctions[j-1], optdp.junctions[j])
+		outboundTravelTime := segment.TravelTime(distanceMeters, optdp.speedKhm)
+		inboundTravelTime := 0.0
+		if optdp.settings.twoWay {
+			inboundTravelTime = segment.TravelTime(distanceMeters, optdp.settings.inboundSpeedKmh)
+		}
+		score[j] = make([]float64, len(candidates[j]))
+		previous[j] = make([]int, len(candidates[j]))
+		for k := range candidates[j] {
+			score[j][k] = math.Inf(-1)
+			for p := range candidates[j-1] {
+				pairScore := optdp.settings.outboundWeight * wavesBandwidth(FindGreenWavesBetweenIntervals(outboundIntervals[j-1][p], outboundIntervals[j][k], distanceMeters, outboundTravelTime))
+				if optdp.settings.twoWay {
+					pairScore += optdp.settings.inboundWeight * wavesBandwidth(FindGreenWavesBetweenIntervals(inboundIntervals[j][k], inboundIntervals[j-1][p], distanceMeters, inboundTravelTime))
+				}
+				if total := score[j-1][p] + pairScore; total > score[j][k] {
+					score[j][k] = total
+					previous[j][k] = p
+				}
+			}
+		}
+	}
+
+	// Backtrack the pairwise optimal plan
+	best := 0
+	for k := range score[junctionsNum-1] {
+		if score[junctionsNum-1][k] > score[junctionsNum-1][best] {
+			best = k
+		}
+	}
+	optdp.pairwiseScore = score[junctionsNum-1][best]
+	for j := junctionsNum - 1; j >= 0; j-- {
+		offsets[j] = candidates[j][best]
+		if j > 0 {
+			best = previous[j][best]
+		}
+	}
+
+	if _, defaultFitness := optdp.settings.fitness.(DepthWeightedBandwidthFitness); optdp.chainCorrection || !defaultFitness {
+		optdp.correct(offsets, candidates)
+	}
+	if optdp.settings.outputResolution > 0 {
+		offsets = optdp.space.round(offsets, optdp.settings.outputResolution)
+	}
+	optdp.bestFitness = optdp.evaluate(offsets)
+	return offsets
+}
+
+// correct refines offsets junction by junction (coordinate ascent over offsets candidates) using the corridor fitness.
+// Offsets are modified in place.
+func (optdp *OptimizerDP) correct(offsets []float64, candidates [][]float64) {
+	bestFitness := optdp.evaluate(offsets)
+	for pass := 0; pass < dpMaxCorrectionPasses; pass++ {
+		improved := false
+		for j := 0; j < len(offsets); j++ {
+			if len(candidates[j]) < 2 {
+				continue
+			}
+			currentOffset := offsets[j]
+			for _, candidate := range candidates[j] {
+				offsets[j] = candidate
+				if fitness := optdp.evaluate(offsets); fitness > bestFitness+eps {
+					bestFitness = fitness
+					currentOffset = candidate
+					improved = true
+				}
+			}
+			offsets[j] = currentOffset
+		}
+		if !improved {
+			return
+		}
+	}
+}
+
+// evaluate calculates the corridor fitness of offsets. Junctions are not modified.
+func (optdp *OptimizerDP) evaluate(offsets []float64) float64 {
+	return evaluateOffsets(optdp.junctions, optdp.speedKhm, offsets, nil, optdp.settings)
+}
+
+// PairwiseScore returns the weighted sum of pairwise bandwidths of the pairwise optimal plan found by the last Optimize call.
+func (optdp *OptimizerDP) PairwiseScore() float64 {
+	return optdp.pairwiseScore
+}
+
+// BestFitness returns the fitness of the offsets returned by the last Optimize call
+func (optdp *OptimizerDP) BestFitness() float64 {
+	return optdp.bestFitness
+}
+
+// wavesBandwidth returns the total bandwidth of the given green waves.
+func wavesBandwidth(waves []*GreenWave) float64 {
+	total := 0.0
+	for _, wave := range waves {
+		total += wave.bandwidth
+	}
+	return total
+}
@@ -0,0 +1,64 @@
+package greenwave
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizerDP(t *testing.T) {
+	junctions := basicTestJuntions()[:3]
+	step := 5.0
+	optimizer := NewOptimizerDP(junctions, 40, step, false).(*OptimizerDP)
+	offsets := optimizer.Optimize()
+	assert.Equal(t, 0.0, offsets[0], "First offset should be zero")
+	for i, offset := range offsets {
+		assert.InDelta(t, 0.0, offset-step*float64(int(offset/step)), 1e-9, "Offset %d is not on the grid", i)
+	}
+	assert.Equal(t, offsets, NewOptimizerDP(junctions, 40, step, false).Optimize(), "Optimizer should be deterministic")
+
+	// Brute force over the same grid gives the same pairwise optimum
+	pairwise := func(offsets []float64) float64 {
+		for i, junction := range junctions {
+			junction.SetOffset(offsets[i])
+		}
+		total := 0.0
+		for _, segmentWaves := range FindGreenWaves(junctions, 40) {
+			total += wavesBandwidth(segmentWaves)
+		}
+		return total
+	}
+	best := 0.0
+	for second := 0.0; second < 85; second += step {
+		for third := 0.0; third < 85; third += step {
+			if score := pairwise([]float64{0, second, third}); score > best {
+				best = score
+			}
+		}
+	}
+	assert.InDelta(t, best, optimizer.PairwiseScore(), 1e-9, "Pairwise optimum mismatch")
+
+	// Chain-level correction never makes the corridor fitness worse
+	corrected := NewOptimizerDP(junctions, 40, step, true).(*OptimizerDP)
+	corrected.Optimize()
+	assert.GreaterOrEqual(t, corrected.BestFitness(), optimizer.BestFitness()-1e-9, "Correction should not decrease fitness")
+}
+
+// junctionOffsetFitness prefers the given offset of the second junction. It is not captured by pairwise bandwidths at all
+type junctionOffsetFitness struct {
+	offset float64
+}
+
+func (fitness junctionOffsetFitness) Evaluate(corridor *CorridorWaves) float64 {
+	return -math.Abs(corridor.junctions[1].GetOffset() - fitness.offset)
+}
+
+func TestOptimizerDPFitness(t *testing.T) {
+	junctions := basicTestJuntions()
+	// Non-default fitness is maximized via chain correction even if it is not requested
+	optimizer := NewOptimizerDP(junctions, 40, 5.0, false, WithFitness(junctionOffsetFitness{offset: 40})).(*OptimizerDP)
+	bestOffsets := optimizer.Optimize()
+	assert.InDelta(t, 40.0, bestOffsets[1], 1e-9, "Plan should be refined by the selected fitness")
+	assert.InDelta(t, 0.0, optimizer.BestFitness(), 1e-9, "Fitness mismatch")
+}
@@ -1,8 +1,10 @@
 package greenwave
 
 import (
+	"context"
 	"math"
 	"math/rand/v2"
+	"sync"
 )
 
 // OptimizerGenetic implements a genetic algorithm for optimizing traffic light offsets
@@ -25,7 +27,9 @@ func (ioutIndex CrossoverType) String() string {
 // Individual represents a single solution in the genetic algorithm
 type Individual struct {
 	Offsets []float64
-	Fitness float64
+	// Durations contains signal durations of each junction in cycle order. Presented for green split optimization only
+	Durations [][]float64
+	Fitness   float64
 }
 
 // OptimizerGenetic is a genetic algorithm optimizer for traffic light offsets
@@ -45,18 +49,38 @@ type OptimizerGenetic struct {
 	// crossoverType defines the type of crossover to use in the genetic algorithm
 	crossoverType CrossoverType
 	// crossoverFunc is the function used for crossover between two parents
-	crossoverFunc func(cycleLengths []float64, parent1, parent2 *Individual) *Individual
-	// cycleLengths contains the total duration of each junction in seconds
+	crossoverFunc func(random *rand.Rand, space *offsetSpace, parent1, parent2 *Individual) *Individual
+	// cycleLengths contains the total duration of each junction in seconds. Offset of each junction lies within [0; cycle) of its own cycle
 	cycleLengths []float64
+	// space contains allowed offsets of each junction (see OffsetConstraint)
+	space *offsetSpace
 	// bestFitenessHistory keeps track of the best fitness value in each generation
 	bestFitenessHistory []float64
+	// bestFitness is the fitness of the resulting offsets (after rounding to the output resolution)
+	bestFitness float64
+	// greenSplits contains signal duration bounds of each junction. Nil if green split optimization is disabled
+	greenSplits []*greenSplit
+	// bestDurations contains resulting signal durations of each junction in case of green split optimization
+	bestDurations [][]float64
+	// settings contains common optimizer settings (e.g. two-way optimization)
+	settings *OptimizerSettings
 }
 
 // NewOptimizerGenetic creates a new instance of OptimizerGenetic with the provided parameters
-func NewOptimizerGenetic(junctions []*Junction, speedKhm float64, populationSize int, generations int, mutationRate float64, tournamentSize int, crossoverType CrossoverType) Optimizer {
+// Optional settings (e.g. WithTwoWay) could be provided via options.
+func NewOptimizerGenetic(junctions []*Junction, speedKhm float64, populationSize int, generations int, mutationRate float64, tournamentSize int, crossoverType CrossoverType, options ...func(*OptimizerSettings)) Optimizer {
+	settings := newOptimizerSettings(options...)
 	cycleLengths := make([]float64, len(junctions))
+	var greenSplits []*greenSplit
+	if settings.greenSplits {
+		greenSplits = newGreenSplits(junctions, settings.greenSplitMode)
+	}
 	for i, junction := range junctions {
-		cycleLengths[i] = float64(junction.totalDuration)
+		cycleLengths[i] = junction.totalDuration
+		if greenSplits != nil {
+			// Offset range should cover the longest possible cycle
+			cycleLengths[i] = greenSplits[i].maxCycle()
+		}
 	}
 	crossoverFunc := blendCrossover
 	if crossoverType == CROSSOVER_UNIFORM {
@@ -72,54 +96,85 @@ func NewOptimizerGenetic(junctions []*Junction, speedKhm float64, populationSize
 		crossoverType:       crossoverType,
 		crossoverFunc:       crossoverFunc,
 		cycleLengths:        cycleLengths,
+		space:               newOffsetSpace(junctions, cycleLengths),
 		bestFitenessHistory: make([]float64, 0, generations),
+		settings:            settings,
+		greenSplits:         greenSplits,
 	}
 }
 
-func randomFloat(min, max float64) float64 {
-	// Generate a random float64 between min and max
-	return min + (max-min)*rand.Float64()
+func (optga *OptimizerGenetic) createIndividual() *Individual {
+	individual := &Individual{Fitness: 0.0}
+	if optga.greenSplits != nil {
+		individual.Durations = make([][]float64, len(optga.greenSplits))
+		for i, split := range optga.greenSplits {
+			individual.Durations[i] = split.random(optga.settings.random)
+		}
+		alignCycles(optga.greenSplits, individual.Durations)
+	}
+	// Create random offsets within cycles of the individual. The first offset is always 0.0 unless any junction is constrained
+	space := optga.individualSpace(individual)
+	individual.Offsets = space.initial()
+	for _, i := range space.variables {
+		individual.Offsets[i] = space.random(optga.settings.random, i)
+	}
+	return individual
 }
 
-func (optga *OptimizerGenetic) createIndividual() *Individual {
-	// Create a new individual with random offsets
-	offsets := make([]float64, len(optga.cycleLengths))
-	offsets[0] = 0.0 // The first offset is always 0.0
-	for i := 1; i < len(offsets); i++ {
-		offsets[i] = randomFloat(0, optga.cycleLengths[i])
+// individualSpace returns allowed offsets of the individual. In free cycle green split mode offsets are taken modulo
+// cycle lengths implied by signal durations of the individual rather than the longest possible ones
+func (optga *OptimizerGenetic) individualSpace(individual *Individual) *offsetSpace {
+	if optga.settings.greenSplitMode != GREEN_SPLIT_FREE_CYCLE || individual.Durations == nil {
+		return optga.space
+	}
+	cycleLengths := make([]float64, len(individual.Durations))
+	for i, durations := range individual.Durations {
+		for _, duration := range durations {
+			cycleLengths[i] += duration
+		}
 	}
-	return &Individual{Offsets: offsets, Fitness: 0.0}
+	return optga.space.withCycles(cycleLengths)
 }
 
 // EvaluateFitness calculates the fitness of an individual based on the traffic light offsets
+// Junctions are not modified, so it is safe to evaluate individuals concurrently
 func (optga *OptimizerGenetic) evaluateFitness(individual *Individual) float64 {
-	// Apply the offsets to the junctions
-	for i, junction := range optga.junctions {
-		junction.SetOffset(int(individual.Offsets[i]))
+	// Find green waves for the offsets (and signal durations) and evaluate them (both directions in case of two-way optimization)
+	return evaluateOffsets(optga.junctions, optga.speedKhm, individual.Offsets, individual.Durations, optga.settings)
+}
+
+// evaluatePopulation calculates the fitness of every individual of the population.
+// Individuals are evaluated by the pool of workers if more than one worker is configured (see WithWorkers)
+func (optga *OptimizerGenetic) evaluatePopulation(population []*Individual) {
+	if optga.settings.workers < 2 {
+		for _, individual := range population {
+			individual.Fitness = optga.evaluateFitness(individual)
+		}
+		return
 	}
-	// Find green waves
-	greenWavs := FindGreenWaves(optga.junctions, optga.speedKhm)
-	throughGreenWaves := MergeGreenWaves(greenWavs)
-	if len(throughGreenWaves) == 0 {
-		return 0.0 // No green waves found
+	jobs := make(chan *Individual)
+	var wg sync.WaitGroup
+	for w := 0; w < optga.settings.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for individual := range jobs {
+				individual.Fitness = optga.evaluateFitness(individual)
+			}
+		}()
 	}
-	// Calculate total fitness based on the depth and band size of the green waves
-	maxDepth := len(optga.junctions)
-	totalFitness := 0.0
-	for _, wave := range throughGreenWaves {
-		depthRatio := float64(wave.Depth()) / float64(maxDepth)
-		// Square the depth ratio to emphasize deeper wave
-		waveFitness := depthRatio * depthRatio * float64(wave.Bandwidth())
-		totalFitness += waveFitness
+	for _, individual := range population {
+		jobs <- individual
 	}
-	return totalFitness
+	close(jobs)
+	wg.Wait()
 }
 
 func (optga *OptimizerGenetic) selectParent(population []*Individual) *Individual {
 	// Select a parent using tournament selection
 	tournament := make([]*Individual, optga.tournamentSize)
 	for i := range tournament {
-		tournament[i] = population[rand.IntN(len(population))]
+		tournament[i] = population[optga.settings.random.IntN(len(population))]
 	}
 	// Return the individual with the highest fitness in the tournament
 	bestParent := tournament[0]
@@ -132,34 +187,48 @@ func (optga *OptimizerGenetic) selectParent(population []*Individual) *Individua
 }
 
 // blendCrossover performs a blend crossover between two parents
-func blendCrossover(cycleLengths []float64, parent1, parent2 *Individual) *Individual {
+func blendCrossover(random *rand.Rand, space *offsetSpace, parent1, parent2 *Individual) *Individual {
 	// Create a child by blending the offsets of the parents
-	childOffsets := make([]float64, len(cycleLengths))
-	childOffsets[0] = 0.0 // The first offset is always 0.0
-	for i := 1; i < len(childOffsets); i++ {
-		weight := rand.Float64() // Random weight between 0 and 1
+	childOffsets := space.initial()
+	for _, i := range space.variables {
+		weight := random.Float64() // Random weight between 0 and 1
 		offset := weight*parent1.Offsets[i] + (1-weight)*parent2.Offsets[i]
-		childOffsets[i] = math.Mod(offset, cycleLengths[i]) // Ensure offset is within cycle length
+		childOffsets[i] = space.project(i, offset) // Ensure offset is within cycle length and allowed
 	}
 	return &Individual{Offsets: childOffsets, Fitness: 0.0}
 }
 
 // uniformCrossover performs a uniform crossover between two parents
-func uniformCrossover(cycleLengths []float64, parent1, parent2 *Individual) *Individual {
+func uniformCrossover(random *rand.Rand, space *offsetSpace, parent1, parent2 *Individual) *Individual {
 	// Create a child by randomly selecting offsets from each parent
-	childOffsets := make([]float64, len(cycleLengths))
-	childOffsets[0] = 0.0 // The first offset is always 0.0
-	for i := 1; i < len(childOffsets); i++ {
-		if rand.Float64() < 0.5 {
+	childOffsets := space.initial()
+	for _, i := range space.variables {
+		if random.Float64() < 0.5 {
 			childOffsets[i] = parent1.Offsets[i]
 		} else {
 			childOffsets[i] = parent2.Offsets[i]
 		}
-		childOffsets[i] = math.Mod(childOffsets[i], cycleLengths[i]) // Ensure offset is within cycle length
+		childOffsets[i] = space.project(i, childOffsets[i]) // Ensure offset is within cycle length and allowed
 	}
 	return &Individual{Offsets: childOffsets, Fitness: 0.0}
 }
 
+// crossoverDurations blends signal durations of the parents into the child.
+// Convex combination of feasible durations is feasible too, so the cycle length is kept in fixed cycle mode.
+func (optga *OptimizerGenetic) crossoverDurations(child, parent1, parent2 *Individual) {
+	if optga.greenSplits == nil {
+		return
+	}
+	child.Durations = make([][]float64, len(optga.greenSplits))
+	for i := range optga.greenSplits {
+		weight := optga.settings.random.Float64() // Random weight between 0 and 1
+		child.Durations[i] = make([]float64, len(parent1.Durations[i]))
+		for j := range child.Durations[i] {
+			child.Durations[i][j] = weight*parent1.Durations[i][j] + (1-weight)*parent2.Durations[i][j]
+		}
+	}
+}
+
 // mutate applies mutation to an individual based on the current generation
 func (optga *OptimizerGenetic) mutate(individual *Individual, currentGeneration int) {
 	// Calculate the mutation range based on the current generation
@@ -167,34 +236,74 @@ func (optga *OptimizerGenetic) mutate(individual *Individual, currentGeneration
 	// Mutation step is range [-5; 5]
 	maxDelta := 5*(1-progress) + 0.5*progress // Decrease mutation range over generations
 	// Mutate each offset with a probability of mutationRate
-	for i := 1; i < len(individual.Offsets); i++ {
-		if rand.Float64() < optga.mutationRate {
-			delta := randomFloat(-maxDelta, maxDelta)
-			individual.Offsets[i] = math.Mod(individual.Offsets[i]+delta, optga.cycleLengths[i])
+	space := optga.individualSpace(individual)
+	for _, i := range space.variables {
+		if optga.settings.random.Float64() < optga.mutationRate {
+			individual.Offsets[i] = space.perturb(optga.settings.random, i, individual.Offsets[i], maxDelta)
+		}
+	}
+	// Mutate signal durations of each junction with a probability of mutationRate
+	for i, split := range optga.greenSplits {
+		if optga.settings.random.Float64() >= optga.mutationRate {
+			continue
+		}
+		for j := range individual.Durations[i] {
+			individual.Durations[i][j] += randomFloat(optga.settings.random, -maxDelta, maxDelta)
+		}
+		split.repair(individual.Durations[i])
+	}
+	if optga.settings.greenSplitMode == GREEN_SPLIT_FREE_CYCLE && optga.greenSplits != nil {
+		// Cycles of the individual could have been changed by crossover and mutation: bring them back to the common cycle
+		// and keep offsets within them
+		alignCycles(optga.greenSplits, individual.Durations)
+		space = optga.individualSpace(individual)
+		for i := range individual.Offsets {
+			individual.Offsets[i] = space.project(i, individual.Offsets[i])
 		}
 	}
 }
 
 // Optimize runs the genetic algorithm to calculate the optimal offsets for the traffic lights
 func (optga *OptimizerGenetic) Optimize() []float64 {
+	return optga.OptimizeContext(context.Background()).Offsets
+}
+
+// OptimizeContext runs the genetic algorithm until every generation is done, context is cancelled or early stopping criteria is met.
+// Returns the best offsets found so far
+func (optga *OptimizerGenetic) OptimizeContext(ctx context.Context) *OptimizeResult {
+	criteria, cancel := optga.settings.newStopCriteria(ctx)
+	defer cancel()
+	result := &OptimizeResult{StopReason: STOP_COMPLETED}
+	optga.bestFitenessHistory = optga.bestFitenessHistory[:0]
+
 	// Generate the initial population
 	population := make([]*Individual, optga.populationSize)
 	for i := range population {
 		population[i] = optga.createIndividual()
 	}
 
-	bestFitness := 0.0
+	bestFitness := math.Inf(-1)
 	var bestIndividual *Individual
 
 	for generation := 0; generation < optga.generations; generation++ {
 		// Evaluate fitness for each individual in the population
+		optga.evaluatePopulation(population)
 		for _, individual := range population {
-			individual.Fitness = optga.evaluateFitness(individual)
 			if individual.Fitness > bestFitness {
 				bestFitness = individual.Fitness
 				bestIndividual = individual
 			}
 		}
+		optga.bestFitenessHistory = append(optga.bestFitenessHistory, bestFitness)
+		result.Iterations = generation + 1
+		if optga.settings.observed() && !optga.settings.notify(optga.progress(generation, bestIndividual, population)) {
+			result.StopReason = STOP_ABORTED
+			break
+		}
+		if reason, stop := criteria.check(bestFitness); stop {
+			result.StopReason = reason
+			break
+		}
 
 		// Create the next generation
 		newPopulation := make([]*Individual, 0, optga.populationSize)
@@ -205,7 +314,8 @@ func (optga *OptimizerGenetic) Optimize() []float64 {
 			parent1 := optga.selectParent(population)
 			parent2 := optga.selectParent(population)
 			// Perform crossover to create a child
-			child := optga.crossoverFunc(optga.cycleLengths, parent1, parent2)
+			child := optga.crossoverFunc(optga.settings.random, optga.space, parent1, parent2)
+			optga.crossoverDurations(child, parent1, parent2)
 			// Mutate the child
 			optga.mutate(child, generation)
 			// Add the child to the new population
@@ -213,10 +323,48 @@ func (optga *OptimizerGenetic) Optimize() []float64 {
 		}
 
 		population = newPopulation
-		optga.bestFitenessHistory = append(optga.bestFitenessHistory, bestFitness)
+	}
+	bestOffsets := bestIndividual.Offsets
+	bestDurations := bestIndividual.Durations
+	if optga.settings.outputResolution > 0 {
+		// Round the final plan and re-evaluate it, so reported fitness matches deployed offsets
+		if bestDurations != nil {
+			rounded := make([][]float64, len(bestDurations))
+			for i, split := range optga.greenSplits {
+				rounded[i] = split.round(bestDurations[i], optga.settings.outputResolution)
+			}
+			bestDurations = rounded
+		}
+		bestOffsets = optga.individualSpace(&Individual{Durations: bestDurations}).round(bestOffsets, optga.settings.outputResolution)
+		bestFitness = optga.evaluateFitness(&Individual{Offsets: bestOffsets, Durations: bestDurations})
+	}
+	optga.bestFitness = bestFitness
+	optga.bestDurations = bestDurations
+	result.Offsets = bestOffsets
+	result.Fitness = bestFitness
+	return result
+}
 
+// progress collects progress statistics of the population
+func (optga *OptimizerGenetic) progress(generation int, bestIndividual *Individual, population []*Individual) Progress {
+	fitnesses := make([]float64, len(population))
+	offsets := make([][]float64, len(population))
+	for i, individual := range population {
+		fitnesses[i] = individual.Fitness
+		offsets[i] = individual.Offsets
 	}
-	return bestIndividual.Offsets
+	return populationProgress(generation, bestIndividual.Fitness, bestIndividual.Offsets, fitnesses, offsets, optga.cycleLengths)
+}
+
+// BestDurations returns signal durations of each junction (in cycle order, see Junction.GetSignalDurations) found by the last Optimize call.
+// Returns nil if green split optimization is disabled (see WithGreenSplits)
+func (optga *OptimizerGenetic) BestDurations() [][]float64 {
+	return optga.bestDurations
+}
+
+// BestFitness returns the fitness of the offsets returned by the last Optimize call
+func (optga *OptimizerGenetic) BestFitness() float64 {
+	return optga.bestFitness
 }
 
 // BestFitnessHistory returns the history of the best fitness values across generations
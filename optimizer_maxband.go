@@ -0,0 +1,291 @@
+package greenwave
+
+import "math"
+
+// OptimizerMaxband is an exact offsets optimizer for linear corridors based on the classic MAXBAND formulation (Little, 1981).
+// Each junction contributes the single coordinated green window per direction (the longest usable interval of the cycle),
+// all junctions share the cycle of the first one. The mixed-integer program maximizing the weighted sum of outbound and
+// inbound bands is solved by the self-contained branch-and-bound over the simplex method, so the result is deterministic and
+// provably maximal within the formulation.
+type OptimizerMaxband struct {
+	// contains the traffic junctions to optimize
+	junctions []*Junction
+	// speedKhm is the speed in kilometers per hour used for calculating offsets
+	speedKhm float64
+	// space contains allowed offsets of each junction (see OffsetConstraint)
+	space *offsetSpace
+	// settings contains common optimizer settings (e.g. two-way optimization)
+	settings *OptimizerSettings
+	// outboundBandwidth is the outbound band (in seconds) of the optimal solution
+	outboundBandwidth float64
+	// inboundBandwidth is the inbound band (in seconds) of the optimal solution
+	inboundBandwidth float64
+	// bestFitness is the fitness of the resulting offsets (after rounding to the output resolution)
+	bestFitness float64
+	// optimal is true if the resulting offsets are proven optimal within the formulation
+	optimal bool
+}
+
+// NewOptimizerMaxband creates a new instance of OptimizerMaxband.
+// Optional settings (e.g. WithTwoWay) could be provided via options. Inbound band is considered in two-way mode only.
+// All junctions must share the cycle length (see CommonCycle): otherwise Optimize returns initial offsets and Optimal reports false.
+func NewOptimizerMaxband(junctions []*Junction, speedKhm float64, options ...func(*OptimizerSettings)) Optimizer {
+	return &OptimizerMaxband{
+		junctions: junctions,
+		speedKhm:  speedKhm,
+		space:     newOffsetSpace(junctions, junctionCycles(junctions)),
+		settings:  newOptimizerSettings(options...),
+	}
+}
+
+// maxbandWindow is the coordinated green window of a junction in its own cycle frame (offset is not applied)
+type maxbandWindow struct {
+	start    float64
+	duration float64
+}
+
+// coordinatedWindow returns the longest usable green interval of the junction for the direction.
+func coordinatedWindow(junction *Junction, extractSettings *ExtractSettings, junctionIdx int, inbound bool) maxbandWindow {
+	window := maxbandWindow{}
+	for _, interval := range junction.greenIntervals(extractSettings.effectiveGreen, extractSettings.signalGroup(junctionIdx, inbound)) {
+		if duration := interval.End - interval.Start; duration > window.duration {
+			window = maxbandWindow{start: interval.Start, duration: duration}
+		}
+	}
+	return window
+}
+
+// Optimize builds the MAXBAND program from the junctions and solves it.
+//
+// For junction j with outbound window [g_j; g_j + G_j], inbound window [ḡ_j; ḡ_j + Ḡ_j], cumulative outbound travel time T_j
+// from the first junction and cumulative inbound travel time T̄_j from the last junction the program is:
+//
+//	maximize   outboundWeight * b + inboundWeight * b̄
+//	subject to w_j + b <= G_j, w̄_j + b̄ <= Ḡ_j
+//	           w_j - w̄_j - σ + m_j * C = T_j - T̄_j - g_j + ḡ_j, m_1 = 0, m_j integer
+//
+// where w_j (w̄_j) is the time from the window start to the band start. Offsets are then θ_j = T_j - g_j - w_j relative to the first junction.
+//
+// If any junction has offset constraint (see OffsetConstraint) then offsets are absolute: θ_j = T_j - g_j - w_j + s with the common shift s ∈ [0; C],
+// so no junction is pinned at zero. Each constrained junction adds integer k_j and the rows:
+//
+//	fixed offset c:       θ_j + k_j * C = c
+//	window [lo; hi]:      lo <= θ_j + k_j * C <= hi
+//	discrete set {v_i}:   θ_j + k_j * C = Σ z_i * v_i, Σ z_i = 1, z_i ∈ {0, 1}
+func (optmb *OptimizerMaxband) Optimize() []float64 {
+	junctionsNum := len(optmb.junctions)
+	offsets := make([]float64, junctionsNum)
+	optmb.outboundBandwidth, optmb.inboundBandwidth = 0, 0
+	optmb.optimal = false
+	if junctionsNum == 0 {
+		return offsets
+	}
+	cycle, err := CommonCycle(optmb.junctions)
+	if err != nil {
+		// The formulation requires common cycle
+		offsets = optmb.space.initial()
+		optmb.bestFitness = evaluateOffsets(optmb.junctions, optmb.speedKhm, offsets, nil, optmb.settings)
+		return offsets
+	}
+	extractSettings := newExtractSettings(optmb.settings.extractOptions...)
+
+	// Windows and cumulative travel times
+	outboundWindows := make([]maxbandWindow, junctionsNum)
+	inboundWindows := make([]maxbandWindow, junctionsNum)
+	outboundTravel := make([]float64, junctionsNum)
+	inboundTravel := make([]float64, junctionsNum)
+	for j, junction := range optmb.junctions {
+		outboundWindows[j] = coordinatedWindow(junction, extractSettings, j, false)
+		inboundWindows[j] = coordinatedWindow(junction, extractSettings, j, true)
+		if j > 0 {
+			segment := extractSettings.segment(j - 1)
+			distanceMeters := segment.Distance(optmb.junctions[j-1], junction)
+			outboundTravel[j] = outboundTravel[j-1] + segment.TravelTime(distanceMeters, optmb.speedKhm)
+		}
+	}
+	for j := junctionsNum - 2; j >= 0; j-- {
+		segment := extractSettings.segment(j)
+		distanceMeters := segment.Distance(optmb.junctions[j], optmb.junctions[j+1])
+		inboundTravel[j] = inboundTravel[j+1] + segment.TravelTime(distanceMeters, optmb.settings.inboundSpeedKmh)
+	}
+
+	// Variables: b, b̄, σ, w_1..w_n, w̄_1..w̄_n, m_2..m_n and variables of offset constraints (see addOffsetConstraints)
+	const bIdx, bInboundIdx, sigmaIdx = 0, 1, 2
+	wIdx := func(j int) int { return 3 + j }
+	wInboundIdx := func(j int) int { return 3 + junctionsNum + j }
+	mIdx := func(j int) int { return 3 + 2*junctionsNum + j - 1 }
+	varsNum := 3 + 3*junctionsNum - 1
+	shiftIdx := -1
+	if optmb.space.constrained {
+		shiftIdx = varsNum
+		varsNum += optmb.space.constraintVarsNum(cycle)
+	}
+	program := &mixedIntegerProgram{
+		objective: make([]float64, varsNum),
+		lower:     make([]float64, varsNum),
+		upper:     make([]float64, varsNum),
+		integer:   make([]bool, varsNum),
+	}
+	for j := range program.upper {
+		program.upper[j] = math.Inf(1)
+	}
+	newRow := func() []float64 { return make([]float64, varsNum) }
+	program.objective[bIdx] = optmb.settings.outboundWeight
+	for j := 0; j < junctionsNum; j++ {
+		row := newRow()
+		row[wIdx(j)], row[bIdx] = 1, 1
+		program.constraints = append(program.constraints, linearConstraint{coefficients: row, kind: constraintLE, rhs: outboundWindows[j].duration})
+	}
+	if !optmb.settings.twoWay {
+		// Inbound variables are not used: fix them at zero
+		program.upper[bInboundIdx], program.upper[sigmaIdx] = 0, 0
+		for j := 0; j < junctionsNum; j++ {
+			program.upper[wInboundIdx(j)] = 0
+			if j > 0 {
+				program.upper[mIdx(j)] = 0
+			}
+		}
+	} else {
+		program.objective[bInboundIdx] = optmb.settings.inboundWeight
+		for j := 0; j < junctionsNum; j++ {
+			row := newRow()
+			row[wInboundIdx(j)], row[bInboundIdx] = 1, 1
+			program.constraints = append(program.constraints, linearConstraint{coefficients: row, kind: constraintLE, rhs: inboundWindows[j].duration})
+		}
+		rhs := func(j int) float64 {
+			return outboundTravel[j] - inboundTravel[j] - outboundWindows[j].start + inboundWindows[j].start
+		}
+		// σ is determined by the first junction (m_1 = 0)
+		sigmaLower := -inboundWindows[0].duration - rhs(0)
+		sigmaUpper := outboundWindows[0].duration - rhs(0)
+		program.lower[sigmaIdx], program.upper[sigmaIdx] = sigmaLower, sigmaUpper
+		for j := 0; j < junctionsNum; j++ {
+			row := newRow()
+			row[wIdx(j)], row[wInboundIdx(j)], row[sigmaIdx] = 1, -1, -1
+			if j > 0 {
+				row[mIdx(j)] = cycle
+				program.integer[mIdx(j)] = true
+				program.lower[mIdx(j)] = math.Ceil((rhs(j) - outboundWindows[j].duration + sigmaLower) / cycle)
+				program.upper[mIdx(j)] = math.Floor((rhs(j) + inboundWindows[j].duration + sigmaUpper) / cycle)
+			}
+			program.constraints = append(program.constraints, linearConstraint{coefficients: row, kind: constraintEQ, rhs: rhs(j)})
+		}
+	}
+	if optmb.space.constrained {
+		bases := make([]float64, junctionsNum)
+		for j := range bases {
+			bases[j] = outboundTravel[j] - outboundWindows[j].start
+		}
+		optmb.addOffsetConstraints(program, cycle, bases, outboundWindows, wIdx, shiftIdx)
+	}
+
+	solution, _, ok := program.solve()
+	optmb.optimal = ok && !program.truncated
+	if ok {
+		optmb.outboundBandwidth = solution[bIdx]
+		optmb.inboundBandwidth = solution[bInboundIdx]
+		firstOffset := outboundTravel[0] - outboundWindows[0].start - solution[wIdx(0)]
+		if optmb.space.constrained {
+			firstOffset = -solution[shiftIdx]
+		}
+		for j, junction := range optmb.junctions {
+			offset := outboundTravel[j] - outboundWindows[j].start - solution[wIdx(j)]
+			offsets[j] = wrapTime(offset-firstOffset, junction.totalDuration)
+		}
+	}
+	if optmb.settings.outputResolution > 0 {
+		offsets = optmb.space.round(offsets, optmb.settings.outputResolution)
+	} else if optmb.space.constrained {
+		// Get rid of numerical noise of the solver (and keep constraints if the program is infeasible)
+		offsets = optmb.space.round(offsets, 0)
+	}
+	optmb.bestFitness = evaluateOffsets(optmb.junctions, optmb.speedKhm, offsets, nil, optmb.settings)
+	return offsets
+}
+
+// constraintVarsNum returns the number of variables of offset constraints: the common shift, k_j of each constrained junction
+// and z_i of each allowed value of discrete sets. Windows which are not shorter than the cycle do not restrict offsets.
+func (space *offsetSpace) constraintVarsNum(cycle float64) int {
+	varsNum := 1
+	for _, constraint := range space.constraints {
+		switch {
+		case constraint == nil:
+		case constraint.constraintType == OFFSET_CONSTRAINT_WINDOW && constraint.max-constraint.min >= cycle:
+		case constraint.constraintType == OFFSET_CONSTRAINT_DISCRETE && !constraint.fixed():
+			varsNum += 1 + len(constraint.offsets)
+		default:
+			varsNum++
+		}
+	}
+	return varsNum
+}
+
+// addOffsetConstraints adds offset constraints of junctions to the program. Offset of junction j is θ_j = bases[j] - w_j + s,
+// where s is the common shift (variable with shiftIdx index). Variables of constraints follow the shift (see constraintVarsNum).
+func (optmb *OptimizerMaxband) addOffsetConstraints(program *mixedIntegerProgram, cycle float64, bases []float64, windows []maxbandWindow, wIdx func(int) int, shiftIdx int) {
+	varsNum := len(program.objective)
+	program.upper[shiftIdx] = cycle
+	nextIdx := shiftIdx + 1
+	for j, constraint := range optmb.space.constraints {
+		if constraint == nil || (constraint.constraintType == OFFSET_CONSTRAINT_WINDOW && constraint.max-constraint.min >= cycle) {
+			continue
+		}
+		// θ_j + k_j * C lies within [bases[j] - G_j; bases[j] + C] + k_j * C, so k_j bounds follow from the constraint bounds
+		kIdx := nextIdx
+		nextIdx++
+		program.integer[kIdx] = true
+		program.lower[kIdx] = math.Floor((constraint.min-bases[j]-cycle)/cycle) - 1
+		program.upper[kIdx] = math.Ceil((constraint.max-bases[j]+windows[j].duration)/cycle) + 1
+		row := make([]float64, varsNum)
+		row[wIdx(j)], row[shiftIdx], row[kIdx] = -1, 1, cycle
+		switch {
+		case constraint.constraintType == OFFSET_CONSTRAINT_WINDOW:
+			program.constraints = append(program.constraints,
+				linearConstraint{coefficients: row, kind: constraintGE, rhs: constraint.min - bases[j]},
+				linearConstraint{coefficients: row, kind: constraintLE, rhs: constraint.max - bases[j]},
+			)
+		case constraint.constraintType == OFFSET_CONSTRAINT_DISCRETE && !constraint.fixed():
+			choiceRow := make([]float64, varsNum)
+			for _, allowed := range constraint.offsets {
+				program.integer[nextIdx] = true
+				program.upper[nextIdx] = 1
+				row[nextIdx] = -allowed
+				choiceRow[nextIdx] = 1
+				nextIdx++
+			}
+			program.constraints = append(program.constraints,
+				linearConstraint{coefficients: row, kind: constraintEQ, rhs: -bases[j]},
+				linearConstraint{coefficients: choiceRow, kind: constraintEQ, rhs: 1},
+			)
+		default:
+			program.constraints = append(program.constraints, linearConstraint{coefficients: row, kind: constraintEQ, rhs: constraint.min - bases[j]})
+		}
+	}
+}
+
+// Optimal returns true if offsets found by the last Optimize call are proven optimal within the formulation.
+// False means that junctions do not share common cycle, the program is infeasible or branch-and-bound has reached
+// its node limit and the best solution found so far has been returned.
+func (optmb *OptimizerMaxband) Optimal() bool {
+	return optmb.optimal
+}
+
+// Bandwidths returns outbound and inbound bands (in seconds) of the solution found by the last Optimize call.
+// Inbound band is zero unless two-way mode is enabled.
+func (optmb *OptimizerMaxband) Bandwidths() (outbound float64, inbound float64) {
+	return optmb.outboundBandwidth, optmb.inboundBandwidth
+}
+
+// BestFitness returns the fitness of the offsets returned by the last Optimize call
+func (optmb *OptimizerMaxband) BestFitness() float64 {
+	return optmb.bestFitness
+}
+
+// junctionCycles returns cycle lengths of the junctions in seconds.
+func junctionCycles(junctions []*Junction) []float64 {
+	cycles := make([]float64, len(junctions))
+	for i, junction := range junctions {
+		cycles[i] = junction.totalDuration
+	}
+	return cycles
+}
@@ -0,0 +1,106 @@
+package greenwave
+
+import (
+	"testing"
+
+	"github.com/LdDl/greenwave/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimplexMaximize(t *testing.T) {
+	solution, value, ok := simplexMaximize([]float64{3, 2}, []linearConstraint{
+		{coefficients: []float64{1, 1}, kind: constraintLE, rhs: 4},
+		{coefficients: []float64{1, 3}, kind: constraintLE, rhs: 6},
+		{coefficients: []float64{1, 0}, kind: constraintLE, rhs: 3},
+	})
+	assert.True(t, ok, "Program should be solved")
+	assert.InDelta(t, 11.0, value, 1e-9, "Objective mismatch")
+	assert.InDeltaSlice(t, []float64{3, 1}, solution, 1e-9, "Solution mismatch")
+
+	_, _, ok = simplexMaximize([]float64{1}, []linearConstraint{
+		{coefficients: []float64{1}, kind: constraintGE, rhs: 2},
+		{coefficients: []float64{1}, kind: constraintLE, rhs: 1},
+	})
+	assert.False(t, ok, "Infeasible program should not be solved")
+
+	program := &mixedIntegerProgram{
+		objective:   []float64{1, 1},
+		constraints: []linearConstraint{{coefficients: []float64{2, 2}, kind: constraintLE, rhs: 5}},
+		lower:       []float64{0, 0},
+		upper:       []float64{10, 10},
+		integer:     []bool{true, true},
+	}
+	solution, value, ok = program.solve()
+	assert.True(t, ok, "Program should be solved")
+	assert.InDelta(t, 2.0, value, 1e-9, "Integer objective mismatch")
+	assert.InDelta(t, 2.0, solution[0]+solution[1], 1e-9, "Integer solution mismatch")
+}
+
+func TestOptimizerMaxband(t *testing.T) {
+	// One-way: the band is limited by the narrowest coordinated window only
+	junctions := basicTestJuntions()
+	optimizer := NewOptimizerMaxband(junctions, 40).(*OptimizerMaxband)
+	offsets := optimizer.Optimize()
+	assert.Equal(t, len(junctions), len(offsets), "Expected offset for each junction")
+	assert.Equal(t, 0.0, offsets[0], "First offset should be zero")
+	expected := -1.0
+	for _, junction := range junctions {
+		longest := 0.0
+		for _, interval := range junction.GetGreenIntervals() {
+			if interval.End-interval.Start > longest {
+				longest = interval.End - interval.Start
+			}
+		}
+		if expected < 0 || longest < expected {
+			expected = longest
+		}
+	}
+	outbound, inbound := optimizer.Bandwidths()
+	assert.InDelta(t, expected, outbound, 1e-9, "Outbound band mismatch")
+	assert.Equal(t, 0.0, inbound, "Inbound band should not be considered in one-way mode")
+
+	// Two-way: 50% greens and 15 s of travel time on 60 s cycle give 30 s of total band at most
+	cycle := func() []*Phase {
+		return []*Phase{NewPhase(0, []*Signal{NewSignal(30, color.GREEN), NewSignal(30, color.RED)})}
+	}
+	junctions = []*Junction{
+		NewJunction(cycle(), WithPoint(Point{X: 0, Y: 0})),
+		NewJunction(cycle(), WithPoint(Point{X: 0, Y: 150})),
+	}
+	optimizer = NewOptimizerMaxband(junctions, 36, WithTwoWay(36, 1, 1)).(*OptimizerMaxband)
+	optimizer.Optimize()
+	outbound, inbound = optimizer.Bandwidths()
+	assert.InDelta(t, 30.0, outbound+inbound, 1e-6, "Total two-way band mismatch")
+}
+
+func TestOptimizerMaxbandOptimal(t *testing.T) {
+	program := &mixedIntegerProgram{
+		objective:   []float64{1, 1},
+		constraints: []linearConstraint{{coefficients: []float64{2, 2}, kind: constraintLE, rhs: 5}},
+		lower:       []float64{0, 0},
+		upper:       []float64{10, 10},
+		integer:     []bool{true, true},
+		maxNodes:    1,
+	}
+	_, _, ok := program.solve()
+	assert.False(t, ok, "No integer solution should be found within a single node")
+	assert.True(t, program.truncated, "Reaching the node limit should be reported")
+	program.maxNodes = 0
+	_, _, ok = program.solve()
+	assert.True(t, ok, "Program should be solved")
+	assert.False(t, program.truncated, "Complete search should not be reported as truncated")
+
+	junctions := basicTestJuntions()
+	optimizer := NewOptimizerMaxband(junctions, 40).(*OptimizerMaxband)
+	optimizer.Optimize()
+	assert.True(t, optimizer.Optimal(), "Solution should be proven optimal")
+
+	// Junctions without common cycle could not be formulated
+	junctions[1].SetSignalDurations([]float64{25, 35, 5, 10, 10, 5})
+	_, err := CommonCycle(junctions)
+	assert.Error(t, err, "Expected error for different cycle lengths")
+	optimizer = NewOptimizerMaxband(junctions, 40).(*OptimizerMaxband)
+	offsets := optimizer.Optimize()
+	assert.False(t, optimizer.Optimal(), "Solution without common cycle should not be reported optimal")
+	assert.Equal(t, make([]float64, len(junctions)), offsets, "Initial offsets should be returned without common cycle")
+}
@@ -0,0 +1,380 @@
+package greenwave
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// Objectives is the vector of objectives of the multi-objective optimization. Every objective is maximized.
+type Objectives struct {
+	// OutboundBandwidth is the bandwidth of the widest outbound through green wave which passes the whole corridor
+	OutboundBandwidth float64
+	// InboundBandwidth is the bandwidth of the widest inbound through green wave which passes the whole corridor (two-way optimization only)
+	InboundBandwidth float64
+	// ThroughWaves is the number of through green waves (both directions for two-way optimization)
+	ThroughWaves int
+	// Coverage is the share of corridor segments passed by at least one green wave (averaged over directions for two-way optimization)
+	Coverage float64
+}
+
+// values returns objectives as a vector
+func (objectives Objectives) values() [4]float64 {
+	return [4]float64{objectives.OutboundBandwidth, objectives.InboundBandwidth, float64(objectives.ThroughWaves), objectives.Coverage}
+}
+
+// dominates returns true if objectives are not worse than other ones in every objective and better in at least one.
+func (objectives Objectives) dominates(other Objectives) bool {
+	values, otherValues := objectives.values(), other.values()
+	better := false
+	for k := range values {
+		if values[k] < otherValues[k]-eps {
+			return false
+		}
+		if values[k] > otherValues[k]+eps {
+			better = true
+		}
+	}
+	return better
+}
+
+// ParetoSolution is a single non-dominated solution of the multi-objective optimization
+type ParetoSolution struct {
+	// Offsets contains offsets of junctions
+	Offsets []float64
+	// Objectives is the objective vector of the offsets
+	Objectives Objectives
+	// rank is the index of the non-domination front (0 is the best one)
+	rank int
+	// crowding is the crowding distance within the front
+	crowding float64
+}
+
+// OptimizerNSGA2 is a multi-objective optimizer for traffic light offsets based on NSGA-II (Deb et al., 2002).
+// Instead of the single scalar fitness it keeps a trade-off between outbound bandwidth, inbound bandwidth,
+// number of through green waves and corridor coverage and returns the Pareto front of offsets.
+type OptimizerNSGA2 struct {
+	// contains the traffic junctions to optimize
+	junctions []*Junction
+	// speedKhm is the speed in kilometers per hour used for calculating offsets
+	speedKhm float64
+	// populationSize is the number of individuals in the population
+	populationSize int
+	// generations is the number of generations
+	generations int
+	// mutationRate is the probability of mutation of each offset
+	mutationRate float64
+	// cycleLengths contains the total duration of each junction in seconds
+	cycleLengths []float64
+	// space contains allowed offsets of each junction (see OffsetConstraint)
+	space *offsetSpace
+	// paretoFront contains non-dominated solutions found by the last Optimize call
+	paretoFront []*ParetoSolution
+	// bestFitness is the scalar fitness of the offsets returned by Optimize (after rounding to the output resolution)
+	bestFitness float64
+	// settings contains common optimizer settings (e.g. two-way optimization)
+	settings *OptimizerSettings
+}
+
+// NewOptimizerNSGA2 creates a new instance of OptimizerNSGA2 with the provided parameters
+// Optional settings (e.g. WithTwoWay) could be provided via options. Inbound objectives are considered in two-way mode only.
+func NewOptimizerNSGA2(junctions []*Junction, speedKhm float64, populationSize int, generations int, mutationRate float64, options ...func(*OptimizerSettings)) Optimizer {
+	cycleLengths := junctionCycles(junctions)
+	return &OptimizerNSGA2{
+		junctions:      junctions,
+		speedKhm:       speedKhm,
+		populationSize: populationSize,
+		generations:    generations,
+		mutationRate:   mutationRate,
+		cycleLengths:   cycleLengths,
+		space:          newOffsetSpace(junctions, cycleLengths),
+		settings:       newOptimizerSettings(options...),
+	}
+}
+
+// evaluate calculates the objective vector of offsets. Junctions are not modified.
+func (optns *OptimizerNSGA2) evaluate(offsets []float64) Objectives {
+	junctions := junctionsWithPlan(optns.junctions, offsets, nil)
+	objectives := Objectives{}
+	segmentsNum := len(junctions) - 1
+	outboundSegmentsWaves := FindGreenWaves(junctions, optns.speedKhm, optns.settings.extractOptions...)
+	outboundWaves := MergeGreenWaves(outboundSegmentsWaves)
+	objectives.OutboundBandwidth = corridorBandwidth(outboundWaves, len(junctions))
+	objectives.ThroughWaves = len(outboundWaves)
+	objectives.Coverage = segmentsCoverage(outboundSegmentsWaves, segmentsNum)
+	if optns.settings.twoWay {
+		inboundSegmentsWaves := FindGreenWavesInbound(junctions, optns.settings.inboundSpeedKmh, optns.settings.extractOptions...)
+		inboundWaves := MergeGreenWaves(inboundSegmentsWaves)
+		objectives.InboundBandwidth = corridorBandwidth(inboundWaves, len(junctions))
+		objectives.ThroughWaves += len(inboundWaves)
+		objectives.Coverage = (objectives.Coverage + segmentsCoverage(inboundSegmentsWaves, segmentsNum)) / 2
+	}
+	return objectives
+}
+
+// createSolution creates a solution with random allowed offsets. The first offset is always 0.0 unless any junction is constrained
+func (optns *OptimizerNSGA2) createSolution() *ParetoSolution {
+	offsets := optns.space.initial()
+	for _, i := range optns.space.variables {
+		offsets[i] = optns.space.random(optns.settings.random, i)
+	}
+	return &ParetoSolution{Offsets: offsets, Objectives: optns.evaluate(offsets)}
+}
+
+// selectParent selects a parent by the binary tournament with the crowded comparison (lower rank, then larger crowding distance)
+func (optns *OptimizerNSGA2) selectParent(population []*ParetoSolution) *ParetoSolution {
+	first := population[optns.settings.random.IntN(len(population))]
+	second := population[optns.settings.random.IntN(len(population))]
+	if crowdedLess(second, first) {
+		return second
+	}
+	return first
+}
+
+// offspring creates a child by the circular blend crossover of the parents and mutation
+func (optns *OptimizerNSGA2) offspring(parent1, parent2 *ParetoSolution, currentGeneration int) *ParetoSolution {
+	progress := float64(currentGeneration) / float64(optns.generations)
+	// Mutation step is range [-5; 5]
+	maxDelta := 5*(1-progress) + 0.5*progress // Decrease mutation range over generations
+	offsets := optns.space.initial()
+	for _, i := range optns.space.variables {
+		// Child lies on the shortest arc between the parents
+		offsets[i] = optns.space.project(i, parent1.Offsets[i]+optns.settings.random.Float64()*circularDelta(parent1.Offsets[i], parent2.Offsets[i], optns.cycleLengths[i]))
+		if optns.settings.random.Float64() < optns.mutationRate {
+			offsets[i] = optns.space.perturb(optns.settings.random, i, offsets[i], maxDelta)
+		}
+	}
+	return &ParetoSolution{Offsets: offsets, Objectives: optns.evaluate(offsets)}
+}
+
+// Optimize runs NSGA-II and returns offsets of the Pareto front solution with the best scalar corridor fitness
+// (the same as the genetic algorithm one). The whole front is available via ParetoFront.
+func (optns *OptimizerNSGA2) Optimize() []float64 {
+	return optns.OptimizeContext(context.Background()).Offsets
+}
+
+// OptimizeContext runs NSGA-II until every generation is done, context is cancelled (time budget is respected too) or an observer aborts it.
+// Stagnation and target fitness criteria are not applied since there is no scalar fitness during the search.
+// If observers are subscribed (see WithObserver) then the scalar corridor fitness of the population is evaluated after each generation
+// and the best rank-0 solution found so far is reported.
+// Returns the front solution with the best scalar corridor fitness found so far
+func (optns *OptimizerNSGA2) OptimizeContext(ctx context.Context) *OptimizeResult {
+	criteria, cancel := optns.settings.newStopCriteria(ctx)
+	defer cancel()
+	result := &OptimizeResult{StopReason: STOP_COMPLETED}
+	population := make([]*ParetoSolution, optns.populationSize)
+	for i := range population {
+		population[i] = optns.createSolution()
+	}
+	assignRanksAndCrowding(population)
+	// Best rank-0 solution by scalar fitness is tracked for observers only
+	progressFitness := math.Inf(-1)
+	var progressOffsets []float64
+
+	for generation := 0; generation < optns.generations; generation++ {
+		combined := make([]*ParetoSolution, 0, 2*optns.populationSize)
+		combined = append(combined, population...)
+		for i := 0; i < optns.populationSize; i++ {
+			combined = append(combined, optns.offspring(optns.selectParent(population), optns.selectParent(population), generation))
+		}
+		// Environmental selection: the best fronts, the last one is truncated by crowding distance
+		fronts := assignRanksAndCrowding(combined)
+		next := make([]*ParetoSolution, 0, optns.populationSize)
+		for _, front := range fronts {
+			if len(next)+len(front) > optns.populationSize {
+				sort.SliceStable(front, func(i, j int) bool {
+					return front[i].crowding > front[j].crowding
+				})
+				next = append(next, front[:optns.populationSize-len(next)]...)
+				break
+			}
+			next = append(next, front...)
+		}
+		population = next
+		result.Iterations = generation + 1
+		if optns.settings.observed() {
+			fitnesses := make([]float64, len(population))
+			offsets := make([][]float64, len(population))
+			for i, solution := range population {
+				fitnesses[i] = evaluateOffsets(optns.junctions, optns.speedKhm, solution.Offsets, nil, optns.settings)
+				offsets[i] = solution.Offsets
+				if solution.rank == 0 && fitnesses[i] > progressFitness {
+					progressFitness, progressOffsets = fitnesses[i], solution.Offsets
+				}
+			}
+			if !optns.settings.notify(populationProgress(generation, progressFitness, progressOffsets, fitnesses, offsets, optns.cycleLengths)) {
+				result.StopReason = STOP_ABORTED
+				break
+			}
+		}
+		if reason, stop := criteria.interrupted(); stop {
+			result.StopReason = reason
+			break
+		}
+	}
+
+	candidates := make([]*ParetoSolution, 0, len(population))
+	for _, solution := range population {
+		if solution.rank != 0 {
+			continue
+		}
+		if optns.settings.outputResolution > 0 {
+			// Round the final plans and re-evaluate them, so reported objectives match deployed offsets
+			offsets := optns.space.round(solution.Offsets, optns.settings.outputResolution)
+			solution = &ParetoSolution{Offsets: offsets, Objectives: optns.evaluate(offsets)}
+		}
+		candidates = append(candidates, solution)
+	}
+	optns.paretoFront = nonDominated(candidates)
+
+	bestOffsets := optns.space.initial()
+	optns.bestFitness = math.Inf(-1)
+	for _, solution := range optns.paretoFront {
+		if fitness := evaluateOffsets(optns.junctions, optns.speedKhm, solution.Offsets, nil, optns.settings); fitness > optns.bestFitness {
+			optns.bestFitness = fitness
+			copy(bestOffsets, solution.Offsets)
+		}
+	}
+	result.Offsets, result.Fitness = bestOffsets, optns.bestFitness
+	return result
+}
+
+// ParetoFront returns non-dominated solutions found by the last Optimize call sorted by outbound bandwidth (descending)
+// Returns slice, do not modify it
+func (optns *OptimizerNSGA2) ParetoFront() []*ParetoSolution {
+	return optns.paretoFront
+}
+
+// BestFitness returns the scalar corridor fitness of the offsets returned by the last Optimize call
+func (optns *OptimizerNSGA2) BestFitness() float64 {
+	return optns.bestFitness
+}
+
+// crowdedLess returns true if the first solution is preferred by the crowded comparison operator
+func crowdedLess(first, second *ParetoSolution) bool {
+	if first.rank != second.rank {
+		return first.rank < second.rank
+	}
+	return first.crowding > second.crowding
+}
+
+// assignRanksAndCrowding performs the fast non-dominated sort of solutions, assigns ranks and crowding distances.
+// Returns fronts in ascending order of rank.
+func assignRanksAndCrowding(solutions []*ParetoSolution) [][]*ParetoSolution {
+	dominatedBy := make([][]int, len(solutions))
+	dominationCount := make([]int, len(solutions))
+	current := make([]int, 0)
+	for i := range solutions {
+		for j := range solutions {
+			if i == j {
+				continue
+			}
+			if solutions[i].Objectives.dominates(solutions[j].Objectives) {
+				dominatedBy[i] = append(dominatedBy[i], j)
+			} else if solutions[j].Objectives.dominates(solutions[i].Objectives) {
+				dominationCount[i]++
+			}
+		}
+		if dominationCount[i] == 0 {
+			current = append(current, i)
+		}
+	}
+	fronts := make([][]*ParetoSolution, 0)
+	for rank := 0; len(current) > 0; rank++ {
+		front := make([]*ParetoSolution, len(current))
+		next := make([]int, 0)
+		for k, i := range current {
+			solutions[i].rank = rank
+			front[k] = solutions[i]
+			for _, j := range dominatedBy[i] {
+				dominationCount[j]--
+				if dominationCount[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		assignCrowding(front)
+		fronts = append(fronts, front)
+		current = next
+	}
+	return fronts
+}
+
+// assignCrowding calculates crowding distances of the solutions of a single front.
+// Boundary solutions of every objective get infinite distance.
+func assignCrowding(front []*ParetoSolution) {
+	for _, solution := range front {
+		solution.crowding = 0
+	}
+	sorted := append([]*ParetoSolution(nil), front...)
+	for k := 0; k < len(Objectives{}.values()); k++ {
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Objectives.values()[k] < sorted[j].Objectives.values()[k]
+		})
+		minValue, maxValue := sorted[0].Objectives.values()[k], sorted[len(sorted)-1].Objectives.values()[k]
+		sorted[0].crowding = math.Inf(1)
+		sorted[len(sorted)-1].crowding = math.Inf(1)
+		if maxValue-minValue <= eps {
+			continue
+		}
+		for i := 1; i < len(sorted)-1; i++ {
+			sorted[i].crowding += (sorted[i+1].Objectives.values()[k] - sorted[i-1].Objectives.values()[k]) / (maxValue - minValue)
+		}
+	}
+}
+
+// nonDominated returns non-dominated solutions without duplicated objective vectors sorted by outbound bandwidth (descending).
+func nonDominated(solutions []*ParetoSolution) []*ParetoSolution {
+	front := make([]*ParetoSolution, 0, len(solutions))
+	for i, solution := range solutions {
+		dominated := false
+		for j, other := range solutions {
+			if i != j && other.Objectives.dominates(solution.Objectives) {
+				dominated = true
+				break
+			}
+		}
+		if dominated {
+			continue
+		}
+		duplicate := false
+		for _, kept := range front {
+			if equalObjectives(kept.Objectives, solution.Objectives) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			front = append(front, solution)
+		}
+	}
+	sort.SliceStable(front, func(i, j int) bool {
+		return front[i].Objectives.OutboundBandwidth > front[j].Objectives.OutboundBandwidth
+	})
+	return front
+}
+
+// equalObjectives returns true if objective vectors are equal within tolerance
+func equalObjectives(first, second Objectives) bool {
+	firstValues, secondValues := first.values(), second.values()
+	for k := range firstValues {
+		if math.Abs(firstValues[k]-secondValues[k]) > eps {
+			return false
+		}
+	}
+	return true
+}
+
+// segmentsCoverage returns the share of segments which have at least one green wave.
+func segmentsCoverage(segmentsWaves [][]*GreenWave, segmentsNum int) float64 {
+	if segmentsNum <= 0 {
+		return 0
+	}
+	covered := 0
+	for _, waves := range segmentsWaves {
+		if len(waves) > 0 {
+			covered++
+		}
+	}
+	return float64(covered) / float64(segmentsNum)
+}
@@ -0,0 +1,58 @@
+package greenwave
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignRanksAndCrowding(t *testing.T) {
+	solutions := []*ParetoSolution{
+		{Objectives: Objectives{OutboundBandwidth: 10, ThroughWaves: 1, Coverage: 1}},
+		{Objectives: Objectives{OutboundBandwidth: 5, ThroughWaves: 3, Coverage: 1}},
+		{Objectives: Objectives{OutboundBandwidth: 8, ThroughWaves: 2, Coverage: 1}},
+		{Objectives: Objectives{OutboundBandwidth: 4, ThroughWaves: 1, Coverage: 0.5}},
+		{Objectives: Objectives{OutboundBandwidth: 3, ThroughWaves: 1, Coverage: 0.5}},
+	}
+	fronts := assignRanksAndCrowding(solutions)
+	assert.Len(t, fronts, 3, "Wrong number of fronts")
+	correctRanks := []int{0, 0, 0, 1, 2}
+	for i, solution := range solutions {
+		assert.Equal(t, correctRanks[i], solution.rank, "Solution %d has wrong rank", i)
+	}
+	assert.True(t, math.IsInf(solutions[0].crowding, 1), "Boundary solution should have infinite crowding distance")
+	assert.True(t, math.IsInf(solutions[1].crowding, 1), "Boundary solution should have infinite crowding distance")
+	assert.InDelta(t, 1.0+1.0, solutions[2].crowding, 1e-9, "Wrong crowding distance of the middle solution")
+	assert.True(t, crowdedLess(solutions[2], solutions[3]), "Lower rank should be preferred")
+}
+
+func TestOptimizerNSGA2(t *testing.T) {
+	junctions := basicTestJuntions()
+	optimizer := NewOptimizerNSGA2(junctions, 40, 30, 30, 0.1).(*OptimizerNSGA2)
+	offsets := optimizer.Optimize()
+	assert.Len(t, offsets, len(junctions), "Wrong number of offsets")
+	assert.Equal(t, 0.0, offsets[0], "First offset should be zero")
+
+	front := optimizer.ParetoFront()
+	assert.NotEmpty(t, front, "Pareto front should not be empty")
+	found := false
+	for i, solution := range front {
+		for j, other := range front {
+			assert.False(t, i != j && other.Objectives.dominates(solution.Objectives), "Solution %d is dominated by solution %d", i, j)
+		}
+		if i > 0 {
+			assert.GreaterOrEqual(t, front[i-1].Objectives.OutboundBandwidth, solution.Objectives.OutboundBandwidth, "Front should be sorted by outbound bandwidth")
+		}
+		assert.Zero(t, solution.Objectives.InboundBandwidth, "Inbound bandwidth is considered in two-way mode only")
+		if assert.ObjectsAreEqual(offsets, solution.Offsets) {
+			found = true
+		}
+	}
+	assert.True(t, found, "Best offsets should belong to the Pareto front")
+
+	for i, junction := range junctions {
+		junction.SetOffset(offsets[i])
+	}
+	assert.InDelta(t, corridorFitness(junctions, 40, newOptimizerSettings()), optimizer.BestFitness(), 1e-9, "Best fitness mismatch")
+}
@@ -0,0 +1,99 @@
+package greenwave
+
+import "math"
+
+// Progress describes the state of the optimization after a generation (iteration, temperature step)
+type Progress struct {
+	// Generation is the index of the generation (iteration, temperature step), starting from 0
+	Generation int
+	// BestFitness is the best fitness found so far
+	BestFitness float64
+	// MeanFitness is the mean fitness of the current population
+	MeanFitness float64
+	// WorstFitness is the worst fitness of the current population
+	WorstFitness float64
+	// Diversity is the circular variance of offsets of the current population averaged over junctions: 0 means that every
+	// individual has the same offsets, 1 means that offsets are spread uniformly over cycles
+	Diversity float64
+	// BestOffsets contains the best offsets found so far
+	BestOffsets []float64
+}
+
+// Observer receives progress of the optimization. It is called synchronously from the optimization goroutine after each generation
+// (iteration, temperature step), so it should return quickly.
+type Observer interface {
+	// OnGeneration is called after each generation. Returning false aborts the optimization (best offsets found so far are returned)
+	OnGeneration(progress Progress) bool
+}
+
+// ObserverFunc is an adapter to use ordinary functions as observers
+type ObserverFunc func(progress Progress) bool
+
+// OnGeneration calls f(progress)
+func (f ObserverFunc) OnGeneration(progress Progress) bool {
+	return f(progress)
+}
+
+// WithObserver is an option function that subscribes the observer to the optimization progress. Several observers could be subscribed.
+// Progress is reported by genetic, simulated annealing, particle swarm and NSGA-II optimizers (the latter evaluates scalar fitness
+// of the population for observers only). Deterministic optimizers do not report it.
+func WithObserver(observer Observer) func(*OptimizerSettings) {
+	return func(s *OptimizerSettings) {
+		s.observers = append(s.observers, observer)
+	}
+}
+
+// observed returns true if there is at least one observer, so progress should be collected
+func (s *OptimizerSettings) observed() bool {
+	return len(s.observers) > 0
+}
+
+// notify passes progress to every observer. Returns false if some observer aborted the optimization.
+func (s *OptimizerSettings) notify(progress Progress) bool {
+	for _, observer := range s.observers {
+		if !observer.OnGeneration(progress) {
+			return false
+		}
+	}
+	return true
+}
+
+// populationProgress collects progress statistics of the population.
+func populationProgress(generation int, bestFitness float64, bestOffsets []float64, fitnesses []float64, offsets [][]float64, cycleLengths []float64) Progress {
+	progress := Progress{
+		Generation:   generation,
+		BestFitness:  bestFitness,
+		WorstFitness: math.Inf(1),
+		Diversity:    offsetsDiversity(offsets, cycleLengths),
+		BestOffsets:  append([]float64(nil), bestOffsets...),
+	}
+	for _, fitness := range fitnesses {
+		progress.MeanFitness += fitness
+		progress.WorstFitness = math.Min(progress.WorstFitness, fitness)
+	}
+	if len(fitnesses) > 0 {
+		progress.MeanFitness /= float64(len(fitnesses))
+	} else {
+		progress.WorstFitness = 0
+	}
+	return progress
+}
+
+// offsetsDiversity returns the circular variance (1 - mean resultant length) of offsets averaged over junctions.
+// The first junction is skipped since its offset is always 0 unless offsets are constrained (see OffsetConstraint).
+func offsetsDiversity(offsets [][]float64, cycleLengths []float64) float64 {
+	if len(offsets) == 0 || len(cycleLengths) < 2 {
+		return 0
+	}
+	total := 0.0
+	for j := 1; j < len(cycleLengths); j++ {
+		sumSin, sumCos := 0.0, 0.0
+		for _, individual := range offsets {
+			angle := 2 * math.Pi * individual[j] / cycleLengths[j]
+			sumSin += math.Sin(angle)
+			sumCos += math.Cos(angle)
+		}
+		total += 1 - math.Hypot(sumSin, sumCos)/float64(len(offsets))
+	}
+	return total / float64(len(cycleLengths)-1)
+}
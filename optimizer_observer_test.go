@@ -0,0 +1,76 @@
+package greenwave
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetsDiversity(t *testing.T) {
+	cycleLengths := []float64{80, 80}
+	assert.InDelta(t, 0.0, offsetsDiversity([][]float64{{0, 10}, {0, 10}, {0, 10}}, cycleLengths), 1e-9, "Same offsets should have zero diversity")
+	assert.InDelta(t, 1.0, offsetsDiversity([][]float64{{0, 0}, {0, 20}, {0, 40}, {0, 60}}, cycleLengths), 1e-9, "Uniformly spread offsets should have diversity 1")
+	assert.InDelta(t, 0.0, offsetsDiversity([][]float64{{0, 0}, {0, 80}}, cycleLengths), 1e-9, "Offsets are circular")
+}
+
+func TestOptimizerGeneticObserver(t *testing.T) {
+	junctions := basicTestJuntions()
+	generations := 20
+	progresses := make([]Progress, 0, generations)
+	observer := ObserverFunc(func(progress Progress) bool {
+		progresses = append(progresses, progress)
+		return true
+	})
+	optimizer := NewOptimizerGenetic(junctions, 40, 20, generations, 0.1, 3, CROSSOVER_BLEND, WithSeed(3), WithObserver(observer)).(*OptimizerGenetic)
+	offsets := optimizer.Optimize()
+	assert.Len(t, progresses, generations, "Observer should be called for every generation")
+	history := optimizer.BestFitnessHistory()
+	for i, progress := range progresses {
+		assert.Equal(t, i, progress.Generation, "Wrong generation number")
+		assert.Equal(t, history[i], progress.BestFitness, "Best fitness mismatch in generation %d", i)
+		assert.LessOrEqual(t, progress.WorstFitness, progress.MeanFitness, "Worst fitness should not exceed mean fitness in generation %d", i)
+		assert.LessOrEqual(t, progress.MeanFitness, progress.BestFitness+1e-9, "Mean fitness should not exceed best fitness in generation %d", i)
+		assert.GreaterOrEqual(t, progress.Diversity, 0.0, "Diversity should be non-negative")
+		assert.LessOrEqual(t, progress.Diversity, 1.0, "Diversity should not exceed 1")
+	}
+	assert.Equal(t, offsets, progresses[len(progresses)-1].BestOffsets, "Best offsets of the last generation should be returned")
+
+	// Observer aborts the run
+	aborting := ObserverFunc(func(progress Progress) bool {
+		return progress.Generation < 4
+	})
+	result := NewOptimizerGenetic(junctions, 40, 20, generations, 0.1, 3, CROSSOVER_BLEND, WithObserver(aborting)).(ContextOptimizer).OptimizeContext(context.Background())
+	assert.Equal(t, STOP_ABORTED, result.StopReason, "Wrong stop reason")
+	assert.Equal(t, 5, result.Iterations, "Optimization should be aborted after the fifth generation")
+}
+
+func TestOptimizerNSGA2Observer(t *testing.T) {
+	junctions := basicTestJuntions()
+	generations := 15
+	progresses := make([]Progress, 0, generations)
+	observer := ObserverFunc(func(progress Progress) bool {
+		progresses = append(progresses, progress)
+		return true
+	})
+	NewOptimizerNSGA2(junctions, 40, 20, generations, 0.1, WithSeed(3), WithObserver(observer)).Optimize()
+	assert.Len(t, progresses, generations, "Observer should be called for every generation")
+	for i, progress := range progresses {
+		assert.Equal(t, i, progress.Generation, "Wrong generation number")
+		assert.Len(t, progress.BestOffsets, len(junctions), "Best offsets should be reported in generation %d", i)
+		assert.InDelta(t, evaluateOffsets(junctions, 40, progress.BestOffsets, nil, newOptimizerSettings()), progress.BestFitness, 1e-9, "Best fitness should be the scalar fitness of best offsets in generation %d", i)
+		assert.LessOrEqual(t, progress.WorstFitness, progress.MeanFitness, "Worst fitness should not exceed mean fitness in generation %d", i)
+		if i > 0 {
+			assert.GreaterOrEqual(t, progress.BestFitness, progresses[i-1].BestFitness, "Best fitness found so far should not decrease")
+		}
+	}
+
+	// Observer aborts the run
+	aborting := ObserverFunc(func(progress Progress) bool {
+		return progress.Generation < 4
+	})
+	result := NewOptimizerNSGA2(junctions, 40, 20, generations, 0.1, WithObserver(aborting)).(ContextOptimizer).OptimizeContext(context.Background())
+	assert.Equal(t, STOP_ABORTED, result.StopReason, "Wrong stop reason")
+	assert.Equal(t, 5, result.Iterations, "Optimization should be aborted after the fifth generation")
+	assert.Len(t, result.Offsets, len(junctions), "Best offsets found so far should be returned")
+}
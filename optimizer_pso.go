@@ -0,0 +1,228 @@
+package greenwave
+
+import (
+	"context"
+	"math"
+)
+
+// SwarmTopology defines which particles share their best positions in particle swarm optimization
+type SwarmTopology uint8
+
+const (
+	// SWARM_TOPOLOGY_GLOBAL makes every particle follow the best position of the whole swarm
+	SWARM_TOPOLOGY_GLOBAL SwarmTopology = iota
+	// SWARM_TOPOLOGY_RING makes every particle follow the best position among itself and its two ring neighbours
+	SWARM_TOPOLOGY_RING
+)
+
+var swarmTopologyToStr = [...]string{"global", "ring"}
+
+// String returns the string representation of the SwarmTopology
+func (topology SwarmTopology) String() string {
+	return swarmTopologyToStr[topology]
+}
+
+// Particle represents a single particle of the swarm
+type Particle struct {
+	// Offsets is the current position of the particle
+	Offsets []float64
+	// Velocity is the current velocity of the particle (seconds per iteration for each junction)
+	Velocity []float64
+	// Fitness is the fitness of the current position
+	Fitness float64
+	// BestOffsets is the best position visited by the particle
+	BestOffsets []float64
+	// BestFitness is the fitness of the best visited position
+	BestFitness float64
+}
+
+// OptimizerPSO is a particle swarm optimizer for traffic light offsets.
+// Positions live on the circular offset space of each junction: attraction towards best positions uses
+// the shortest way around the cycle and positions wrap modulo the junction cycle.
+type OptimizerPSO struct {
+	// contains the traffic junctions to optimize
+	junctions []*Junction
+	// speedKhm is the speed in kilometers per hour used for calculating offsets
+	speedKhm float64
+	// swarmSize is the number of particles
+	swarmSize int
+	// iterations is the number of iterations
+	iterations int
+	// inertia is the weight of the previous velocity
+	inertia float64
+	// cognitive is the attraction coefficient towards the particle best position
+	cognitive float64
+	// social is the attraction coefficient towards the neighbourhood best position
+	social float64
+	// topology defines the neighbourhood of particles
+	topology SwarmTopology
+	// cycleLengths contains the total duration of each junction in seconds
+	cycleLengths []float64
+	// space contains allowed offsets of each junction (see OffsetConstraint)
+	space *offsetSpace
+	// bestFitenessHistory keeps track of the best fitness value of the swarm in each iteration
+	bestFitenessHistory []float64
+	// bestFitness is the fitness of the resulting offsets (after rounding to the output resolution)
+	bestFitness float64
+	// settings contains common optimizer settings (e.g. two-way optimization)
+	settings *OptimizerSettings
+}
+
+// NewOptimizerPSO creates a new instance of OptimizerPSO with the provided parameters
+// Optional settings (e.g. WithTwoWay) could be provided via options. Non-positive swarm size is treated as a single particle.
+func NewOptimizerPSO(junctions []*Junction, speedKhm float64, swarmSize int, iterations int, inertia float64, cognitive float64, social float64, topology SwarmTopology, options ...func(*OptimizerSettings)) Optimizer {
+	cycleLengths := junctionCycles(junctions)
+	if swarmSize < 1 {
+		swarmSize = 1 // The swarm should have at least the global best particle
+	}
+	return &OptimizerPSO{
+		junctions:           junctions,
+		speedKhm:            speedKhm,
+		swarmSize:           swarmSize,
+		iterations:          iterations,
+		inertia:             inertia,
+		cognitive:           cognitive,
+		social:              social,
+		topology:            topology,
+		cycleLengths:        cycleLengths,
+		space:               newOffsetSpace(junctions, cycleLengths),
+		bestFitenessHistory: make([]float64, 0, iterations),
+		settings:            newOptimizerSettings(options...),
+	}
+}
+
+// evaluate calculates the corridor fitness of offsets. Junctions are not modified.
+func (optpso *OptimizerPSO) evaluate(offsets []float64) float64 {
+	return evaluateOffsets(optpso.junctions, optpso.speedKhm, offsets, nil, optpso.settings)
+}
+
+// createParticle creates a particle at random allowed position with random velocity. The first offset is always 0.0 unless any junction is constrained
+func (optpso *OptimizerPSO) createParticle() *Particle {
+	particle := &Particle{
+		Offsets:  optpso.space.initial(),
+		Velocity: make([]float64, len(optpso.cycleLengths)),
+	}
+	for _, i := range optpso.space.variables {
+		half := optpso.cycleLengths[i] / 2
+		particle.Offsets[i] = optpso.space.random(optpso.settings.random, i)
+		particle.Velocity[i] = randomFloat(optpso.settings.random, -half, half) / 2
+	}
+	particle.Fitness = optpso.evaluate(particle.Offsets)
+	particle.BestOffsets = append([]float64(nil), particle.Offsets...)
+	particle.BestFitness = particle.Fitness
+	return particle
+}
+
+// neighbourhoodBest returns the index of the particle with the best visited position in the neighbourhood of the given particle.
+func (optpso *OptimizerPSO) neighbourhoodBest(swarm []*Particle, idx int, globalBest int) int {
+	if optpso.topology != SWARM_TOPOLOGY_RING {
+		return globalBest
+	}
+	best := idx
+	for _, neighbour := range []int{(idx + len(swarm) - 1) % len(swarm), (idx + 1) % len(swarm)} {
+		if swarm[neighbour].BestFitness > swarm[best].BestFitness {
+			best = neighbour
+		}
+	}
+	return best
+}
+
+// Optimize runs the particle swarm optimization to calculate the optimal offsets for the traffic lights
+func (optpso *OptimizerPSO) Optimize() []float64 {
+	return optpso.OptimizeContext(context.Background()).Offsets
+}
+
+// OptimizeContext runs the particle swarm optimization until every iteration is done, context is cancelled or early stopping criteria is met.
+// Returns the best offsets found so far
+func (optpso *OptimizerPSO) OptimizeContext(ctx context.Context) *OptimizeResult {
+	criteria, cancel := optpso.settings.newStopCriteria(ctx)
+	defer cancel()
+	result := &OptimizeResult{StopReason: STOP_COMPLETED}
+	optpso.bestFitenessHistory = optpso.bestFitenessHistory[:0]
+	swarm := make([]*Particle, optpso.swarmSize)
+	globalBest := 0
+	for i := range swarm {
+		swarm[i] = optpso.createParticle()
+		if swarm[i].BestFitness > swarm[globalBest].BestFitness {
+			globalBest = i
+		}
+	}
+
+	for iteration := 0; iteration < optpso.iterations; iteration++ {
+		// Neighbourhood bests are taken from the previous iteration (synchronous update)
+		guides := make([][]float64, len(swarm))
+		for i := range swarm {
+			guides[i] = swarm[optpso.neighbourhoodBest(swarm, i, globalBest)].BestOffsets
+		}
+		for i, particle := range swarm {
+			for _, j := range optpso.space.variables {
+				cycle := optpso.cycleLengths[j]
+				half := cycle / 2
+				velocity := optpso.inertia*particle.Velocity[j] +
+					optpso.cognitive*optpso.settings.random.Float64()*circularDelta(particle.Offsets[j], particle.BestOffsets[j], cycle) +
+					optpso.social*optpso.settings.random.Float64()*circularDelta(particle.Offsets[j], guides[i][j], cycle)
+				// Moving further than half of the cycle is the same as moving the other way round
+				particle.Velocity[j] = math.Max(-half, math.Min(half, velocity))
+				// Positions are projected onto allowed offsets (see OffsetConstraint)
+				particle.Offsets[j] = optpso.space.project(j, particle.Offsets[j]+particle.Velocity[j])
+			}
+			particle.Fitness = optpso.evaluate(particle.Offsets)
+			if particle.Fitness > particle.BestFitness {
+				particle.BestFitness = particle.Fitness
+				copy(particle.BestOffsets, particle.Offsets)
+			}
+		}
+		for i, particle := range swarm {
+			if particle.BestFitness > swarm[globalBest].BestFitness {
+				globalBest = i
+			}
+		}
+		optpso.bestFitenessHistory = append(optpso.bestFitenessHistory, swarm[globalBest].BestFitness)
+		result.Iterations = iteration + 1
+		if optpso.settings.observed() && !optpso.settings.notify(optpso.progress(iteration, swarm, globalBest)) {
+			result.StopReason = STOP_ABORTED
+			break
+		}
+		if reason, stop := criteria.check(swarm[globalBest].BestFitness); stop {
+			result.StopReason = reason
+			break
+		}
+	}
+
+	bestOffsets := append([]float64(nil), swarm[globalBest].BestOffsets...)
+	if optpso.settings.outputResolution > 0 {
+		// Round the final plan and re-evaluate it, so reported fitness matches deployed offsets
+		bestOffsets = optpso.space.round(bestOffsets, optpso.settings.outputResolution)
+	}
+	optpso.bestFitness = optpso.evaluate(bestOffsets)
+	result.Offsets, result.Fitness = bestOffsets, optpso.bestFitness
+	return result
+}
+
+// progress collects progress statistics of the current positions of the swarm
+func (optpso *OptimizerPSO) progress(iteration int, swarm []*Particle, globalBest int) Progress {
+	fitnesses := make([]float64, len(swarm))
+	offsets := make([][]float64, len(swarm))
+	for i, particle := range swarm {
+		fitnesses[i] = particle.Fitness
+		offsets[i] = particle.Offsets
+	}
+	return populationProgress(iteration, swarm[globalBest].BestFitness, swarm[globalBest].BestOffsets, fitnesses, offsets, optpso.cycleLengths)
+}
+
+// BestFitnessHistory returns the history of the best fitness values of the swarm in each iteration
+// Returns slice, do not modify it
+func (optpso *OptimizerPSO) BestFitnessHistory() []float64 {
+	return optpso.bestFitenessHistory
+}
+
+// BestFitness returns the fitness of the offsets returned by the last Optimize call
+func (optpso *OptimizerPSO) BestFitness() float64 {
+	return optpso.bestFitness
+}
+
+// circularDelta returns the shortest signed shift from one time to another one on the circle of the given period.
+// Result is within [-period/2; period/2).
+func circularDelta(from, to, period float64) float64 {
+	return wrapTime(to-from+period/2, period) - period/2
+}
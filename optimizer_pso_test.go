@@ -0,0 +1,48 @@
+package greenwave
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircularDelta(t *testing.T) {
+	assert.InDelta(t, 10.0, circularDelta(80, 5, 85), 1e-9, "Shortest way goes forward across the cycle end")
+	assert.InDelta(t, -10.0, circularDelta(5, 80, 85), 1e-9, "Shortest way goes backward across the cycle start")
+	assert.InDelta(t, 20.0, circularDelta(10, 30, 85), 1e-9, "Shift within the cycle")
+	assert.InDelta(t, 0.0, circularDelta(42, 42, 85), 1e-9, "Same position")
+}
+
+func TestOptimizerPSO(t *testing.T) {
+	junctions := basicTestJuntions()
+	iterations := 40
+	for _, topology := range []SwarmTopology{SWARM_TOPOLOGY_GLOBAL, SWARM_TOPOLOGY_RING} {
+		optimizer := NewOptimizerPSO(junctions, 40, 20, iterations, 0.7, 1.5, 1.5, topology).(*OptimizerPSO)
+		offsets := optimizer.Optimize()
+		assert.Len(t, offsets, len(junctions), "Topology %s: wrong number of offsets", topology)
+		assert.Equal(t, 0.0, offsets[0], "Topology %s: first offset should be zero", topology)
+		for i, offset := range offsets {
+			assert.GreaterOrEqual(t, offset, 0.0, "Topology %s: offset %d out of cycle", topology, i)
+			assert.Less(t, offset, junctions[i].GetTotalDuration(), "Topology %s: offset %d out of cycle", topology, i)
+		}
+
+		history := optimizer.BestFitnessHistory()
+		assert.Len(t, history, iterations, "Topology %s: wrong history length", topology)
+		for i := 1; i < len(history); i++ {
+			assert.GreaterOrEqual(t, history[i], history[i-1], "Topology %s: best fitness should not decrease", topology)
+		}
+		assert.InDelta(t, history[len(history)-1], optimizer.BestFitness(), 1e-9, "Topology %s: best fitness mismatch", topology)
+	}
+}
+
+func TestOptimizerPSOEmptySwarm(t *testing.T) {
+	junctions := basicTestJuntions()
+	for _, swarmSize := range []int{0, -5} {
+		for _, topology := range []SwarmTopology{SWARM_TOPOLOGY_GLOBAL, SWARM_TOPOLOGY_RING} {
+			optimizer := NewOptimizerPSO(junctions, 40, swarmSize, 10, 0.7, 1.5, 1.5, topology).(*OptimizerPSO)
+			var offsets []float64
+			assert.NotPanics(t, func() { offsets = optimizer.Optimize() }, "Swarm size %d (topology %s) should not panic", swarmSize, topology)
+			assert.Len(t, offsets, len(junctions), "Swarm size %d (topology %s): wrong number of offsets", swarmSize, topology)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package greenwave
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundOffsets(t *testing.T) {
+	offsets := []float64{0, 78.54198539844722, 84.97, -0.04}
+	cycleLengths := []float64{85, 85, 85, 85}
+	rounded := RoundOffsets(offsets, cycleLengths, 0.1)
+	assert.Equal(t, []float64{0, 78.5, 0, 0}, rounded, "Offsets should be rounded to tenths of a second within the cycle")
+	assert.Equal(t, 78.54198539844722, offsets[1], "Source offsets should not be modified")
+
+	rounded = RoundOffsets(offsets, cycleLengths, 0)
+	assert.Equal(t, offsets, rounded, "Zero resolution should keep offsets as is")
+}
+
+func TestOptimizerGeneticOutputResolution(t *testing.T) {
+	junctions := basicTestJuntions()
+	optimizer := NewOptimizerGenetic(junctions, 40, 20, 10, 0.1, 3, CROSSOVER_BLEND, WithOutputResolution(0.5)).(*OptimizerGenetic)
+	bestOffsets := optimizer.Optimize()
+	for i, offset := range bestOffsets {
+		assert.InDelta(t, 0.0, offset*2-float64(int(offset*2)), 1e-9, "Offset %d is not rounded to the output resolution", i)
+		junctions[i].SetOffset(offset)
+	}
+	// Reported fitness is the one of deployed (rounded) offsets
+	assert.InDelta(t, corridorFitness(junctions, 40, newOptimizerSettings()), optimizer.BestFitness(), 1e-9, "Fitness mismatch")
+}
+
+func TestOptimizerSeed(t *testing.T) {
+	junctions := basicTestJuntions()
+	newOptimizers := map[string]func(options ...func(*OptimizerSettings)) Optimizer{
+		"genetic": func(options ...func(*OptimizerSettings)) Optimizer {
+			return NewOptimizerGenetic(junctions, 40, 20, 20, 0.1, 3, CROSSOVER_BLEND, options...)
+		},
+		"genetic_uniform_green_splits": func(options ...func(*OptimizerSettings)) Optimizer {
+			return NewOptimizerGenetic(greenSplitTestJunctions(), 40, 20, 20, 0.1, 3, CROSSOVER_UNIFORM, append(options, WithGreenSplits(GREEN_SPLIT_FIXED_CYCLE))...)
+		},
+		"annealing": func(options ...func(*OptimizerSettings)) Optimizer {
+			return NewOptimizerAnnealing(junctions, 40, 10, COOLING_GEOMETRIC, 0.9, 20, 10, 1, options...)
+		},
+		"pso": func(options ...func(*OptimizerSettings)) Optimizer {
+			return NewOptimizerPSO(junctions, 40, 10, 20, 0.7, 1.5, 1.5, SWARM_TOPOLOGY_RING, options...)
+		},
+		"nsga2": func(options ...func(*OptimizerSettings)) Optimizer {
+			return NewOptimizerNSGA2(junctions, 40, 20, 10, 0.1, options...)
+		},
+	}
+	for name, newOptimizer := range newOptimizers {
+		offsets := newOptimizer(WithSeed(42)).Optimize()
+		assert.Equal(t, offsets, newOptimizer(WithSeed(42)).Optimize(), "Optimizer %s: same seed should give the same offsets", name)
+		assert.Equal(t, offsets, newOptimizer(WithRandSource(rand.NewPCG(42, 42))).Optimize(), "Optimizer %s: same random source should give the same offsets", name)
+	}
+}
+
+func TestEvaluateOffsets(t *testing.T) {
+	junctions := basicTestJuntions()
+	offsets := []float64{0, 78.5, 75.5, 84}
+	fitness := EvaluateOffsets(junctions, 40, offsets, nil)
+	for i, junction := range junctions {
+		assert.Equal(t, 0.0, junction.GetOffset(), "Junction %d should not be modified", i)
+	}
+	for i, junction := range junctions {
+		junction.SetOffset(offsets[i])
+	}
+	assert.InDelta(t, corridorFitness(junctions, 40, newOptimizerSettings()), fitness, 1e-9, "Fitness mismatch")
+
+	// Signal durations are applied to copies only
+	splitJunctions := greenSplitTestJunctions()
+	durations := make([][]float64, len(splitJunctions))
+	for i, junction := range splitJunctions {
+		durations[i] = junction.GetSignalDurations()
+		durations[i][0] += 5
+		durations[i][1] -= 5
+	}
+	EvaluateOffsets(splitJunctions, 40, make([]float64, len(splitJunctions)), durations)
+	for i, junction := range splitJunctions {
+		assert.NotEqual(t, durations[i], junction.GetSignalDurations(), "Junction %d durations should not be modified", i)
+	}
+}
+
+func TestOptimizerGeneticWorkers(t *testing.T) {
+	junctions := basicTestJuntions()
+	sequential := NewOptimizerGenetic(junctions, 40, 30, 20, 0.1, 3, CROSSOVER_BLEND, WithSeed(7)).(*OptimizerGenetic)
+	parallel := NewOptimizerGenetic(junctions, 40, 30, 20, 0.1, 3, CROSSOVER_BLEND, WithSeed(7), WithWorkers(4)).(*OptimizerGenetic)
+	assert.Equal(t, sequential.Optimize(), parallel.Optimize(), "Result should not depend on the number of workers")
+	assert.Equal(t, sequential.BestFitnessHistory(), parallel.BestFitnessHistory(), "History should not depend on the number of workers")
+}
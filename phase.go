@@ -7,12 +7,12 @@ type Phase struct {
 	// List of signals that define the phase
 	Signals []*Signal
 	// Total duration of the phase in seconds, calculated from the signals
-	totalSeconds int
+	totalSeconds float64
 }
 
 // NewPhase creates a new Phase instance with the specified ID and signals.
 func NewPhase(id int, signals []*Signal) *Phase {
-	totalSeconds := 0
+	totalSeconds := 0.0
 	for _, signal := range signals {
 		totalSeconds += signal.Duration
 	}
@@ -24,6 +24,6 @@ func NewPhase(id int, signals []*Signal) *Phase {
 }
 
 // GetTotalSeconds returns the total duration of the phase in seconds.
-func (p *Phase) GetTotalSeconds() int {
+func (p *Phase) GetTotalSeconds() float64 {
 	return p.totalSeconds
 }
@@ -1,7 +1,54 @@
 package greenwave
 
+import "math"
+
+// CRS is a coordinate reference system of the point
+type CRS uint8
+
+const (
+	// CRS_PLANAR is a planar coordinate system where X and Y are given in meters
+	CRS_PLANAR CRS = iota
+	// CRS_WGS84 is a geographic coordinate system where X is longitude and Y is latitude in degrees
+	CRS_WGS84
+)
+
+var crsToStr = [...]string{"planar", "wgs84"}
+
+// String returns the string representation of the CRS
+func (ioutIndex CRS) String() string {
+	return crsToStr[ioutIndex]
+}
+
 // Point in 2D space.
+// For CRS_WGS84 X is longitude and Y is latitude (both in degrees).
 type Point struct {
 	X float64
 	Y float64
+	// Coordinate reference system. Default is planar meters
+	CRS CRS
+}
+
+// NewPointWGS84 creates a new geographic point from longitude and latitude in degrees.
+func NewPointWGS84(lon, lat float64) Point {
+	return Point{X: lon, Y: lat, CRS: CRS_WGS84}
+}
+
+// DistanceTo returns distance in meters between two points.
+// Planar points use Euclidean distance; WGS84 points use geodesic distance on the ellipsoid (see DistanceVincenty).
+// Both points are expected to share the same coordinate reference system: CRS of the receiver is used, so the distance
+// between points of different systems is meaningless (e.g. degrees are taken as meters). Validate CRS of input points beforehand.
+func (p Point) DistanceTo(other Point) float64 {
+	if p.CRS == CRS_WGS84 {
+		return DistanceVincenty(p, other)
+	}
+	return math.Sqrt(math.Pow(p.X-other.X, 2) + math.Pow(p.Y-other.Y, 2))
+}
+
+// PolylineLength returns length in meters of the polyline defined by points.
+func PolylineLength(points []Point) float64 {
+	length := 0.0
+	for i := 1; i < len(points); i++ {
+		length += points[i-1].DistanceTo(points[i])
+	}
+	return length
 }
@@ -0,0 +1,138 @@
+package greenwave
+
+// Segment represents a corridor link between two consecutive junctions.
+// It allows to define real road distance, speed or explicit travel time instead of deriving them from junction locations.
+type Segment struct {
+	// DistanceMeters is the distance between junctions in meters. If it is not positive then length of Geometry or straight line distance between junctions points is used
+	DistanceMeters float64
+	// Geometry is an optional road polyline from the first junction to the second one
+	Geometry []Point
+	// SpeedKmh is the design speed on the segment in km/h. If it is not positive then corridor desired speed is used
+	SpeedKmh float64
+	// TravelTimeSeconds is an explicit travel time in seconds. If it is positive then it takes precedence over distance and speed
+	TravelTimeSeconds float64
+	// MinSpeedKmh is the minimum allowed speed on the segment in km/h. Zero means no limit
+	MinSpeedKmh float64
+	// MaxSpeedKmh is the maximum allowed speed on the segment in km/h. Zero means no limit
+	MaxSpeedKmh float64
+}
+
+// NewSegment creates a new Segment instance with the specified distance in meters
+func NewSegment(distanceMeters float64, options ...func(*Segment)) *Segment {
+	segment := &Segment{
+		DistanceMeters: distanceMeters,
+	}
+	for _, option := range options {
+		option(segment)
+	}
+	return segment
+}
+
+// WithSegmentGeometry is an option function that sets the road polyline for the segment.
+func WithSegmentGeometry(geometry []Point) func(*Segment) {
+	return func(s *Segment) {
+		s.Geometry = geometry
+	}
+}
+
+// WithSegmentSpeed is an option function that sets the design speed for the segment.
+func WithSegmentSpeed(speedKmh float64) func(*Segment) {
+	return func(s *Segment) {
+		s.SpeedKmh = speedKmh
+	}
+}
+
+// WithSegmentTravelTime is an option function that sets the explicit travel time for the segment.
+func WithSegmentTravelTime(travelTimeSeconds float64) func(*Segment) {
+	return func(s *Segment) {
+		s.TravelTimeSeconds = travelTimeSeconds
+	}
+}
+
+// WithSegmentSpeedLimits is an option function that sets the minimum and maximum allowed speed for the segment.
+func WithSegmentSpeedLimits(minSpeedKmh, maxSpeedKmh float64) func(*Segment) {
+	return func(s *Segment) {
+		s.MinSpeedKmh = minSpeedKmh
+		s.MaxSpeedKmh = maxSpeedKmh
+	}
+}
+
+// Distance returns distance in meters between two junctions.
+// Explicit distance takes precedence, then length of the road polyline is used.
+// Segment might be nil: straight line (or geodesic for WGS84 points) distance between junctions points is used as fallback.
+func (s *Segment) Distance(junctionOne, junctionTwo *Junction) float64 {
+	if s != nil && s.DistanceMeters > 0 {
+		return s.DistanceMeters
+	}
+	if s != nil && len(s.Geometry) > 1 {
+		return PolylineLength(s.Geometry)
+	}
+	return junctionOne.point.DistanceTo(junctionTwo.point)
+}
+
+// Speed returns speed in km/h on the segment.
+// Segment speed takes precedence over desired speed and then the result is limited by minimum and maximum allowed speed.
+// Segment might be nil: desired speed is returned as is.
+func (s *Segment) Speed(desiredSpeedKmh float64) float64 {
+	if s == nil {
+		return desiredSpeedKmh
+	}
+	speedKmh := desiredSpeedKmh
+	if s.SpeedKmh > 0 {
+		speedKmh = s.SpeedKmh
+	}
+	return s.limitSpeed(speedKmh)
+}
+
+// limitSpeed limits the given speed by minimum and maximum allowed speed of the segment.
+func (s *Segment) limitSpeed(speedKmh float64) float64 {
+	if s == nil {
+		return speedKmh
+	}
+	if s.MinSpeedKmh > 0 && speedKmh < s.MinSpeedKmh {
+		speedKmh = s.MinSpeedKmh
+	}
+	if s.MaxSpeedKmh > 0 && speedKmh > s.MaxSpeedKmh {
+		speedKmh = s.MaxSpeedKmh
+	}
+	return speedKmh
+}
+
+// TravelTime returns travel time in seconds over the given distance.
+// Explicit travel time of the segment takes precedence, otherwise it is derived from the segment speed (see Speed).
+// Segment might be nil: travel time is derived from desired speed.
+func (s *Segment) TravelTime(distanceMeters, desiredSpeedKmh float64) float64 {
+	if s != nil && s.TravelTimeSeconds > 0 {
+		return s.TravelTimeSeconds
+	}
+	speedMs := s.Speed(desiredSpeedKmh) / 3.6
+	return distanceMeters / speedMs
+}
+
+// TravelTimeRange returns minimum and maximum travel time in seconds over the given distance for every speed within [minSpeedKmh, maxSpeedKmh].
+// Speeds are limited by minimum and maximum allowed speed of the segment. Explicit travel time of the segment gives degenerate range.
+// Segment might be nil: speeds are used as is.
+func (s *Segment) TravelTimeRange(distanceMeters, minSpeedKmh, maxSpeedKmh float64) (minTravelTime float64, maxTravelTime float64) {
+	if s != nil && s.TravelTimeSeconds > 0 {
+		return s.TravelTimeSeconds, s.TravelTimeSeconds
+	}
+	minTravelTime = distanceMeters / (s.limitSpeed(maxSpeedKmh) / 3.6)
+	maxTravelTime = distanceMeters / (s.limitSpeed(minSpeedKmh) / 3.6)
+	return minTravelTime, maxTravelTime
+}
+
+// GeometryInDirection returns the road polyline in travel direction: reversed copy for the inbound direction.
+// Segment might be nil: nil is returned.
+func (s *Segment) GeometryInDirection(reversed bool) []Point {
+	if s == nil || len(s.Geometry) == 0 {
+		return nil
+	}
+	if !reversed {
+		return s.Geometry
+	}
+	geometry := make([]Point, len(s.Geometry))
+	for i, point := range s.Geometry {
+		geometry[len(s.Geometry)-1-i] = point
+	}
+	return geometry
+}
@@ -0,0 +1,92 @@
+package greenwave
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentTravelTime(t *testing.T) {
+	junctionOne := NewJunction(nil, WithPoint(Point{X: 0, Y: 0}))
+	junctionTwo := NewJunction(nil, WithPoint(Point{X: 0, Y: 200}))
+
+	// Nil segment falls back to straight line distance and desired speed
+	var segment *Segment
+	assert.InDelta(t, 200.0, segment.Distance(junctionOne, junctionTwo), 1e-9, "Expected straight line distance")
+	assert.InDelta(t, 18.0, segment.TravelTime(200, 40), 1e-9, "Expected travel time derived from desired speed")
+
+	// Distance and speed from segment
+	segment = NewSegment(300, WithSegmentSpeed(36))
+	assert.InDelta(t, 300.0, segment.Distance(junctionOne, junctionTwo), 1e-9, "Expected segment distance")
+	assert.InDelta(t, 30.0, segment.TravelTime(300, 40), 1e-9, "Expected travel time derived from segment speed")
+
+	// Speed limits are applied to desired speed
+	segment = NewSegment(300, WithSegmentSpeedLimits(20, 36))
+	assert.InDelta(t, 36.0, segment.Speed(50), 1e-9, "Expected speed limited by max speed")
+	assert.InDelta(t, 20.0, segment.Speed(10), 1e-9, "Expected speed limited by min speed")
+
+	// Explicit travel time takes precedence
+	segment = NewSegment(300, WithSegmentSpeed(36), WithSegmentTravelTime(25))
+	assert.InDelta(t, 25.0, segment.TravelTime(300, 40), 1e-9, "Expected explicit travel time")
+}
+
+func TestFindGreenWavesWithSegments(t *testing.T) {
+	junctions := basicTestJuntions()
+	desiredSpeedKmh := 40.0
+	segments := []*Segment{
+		nil,
+		NewSegment(400, WithSegmentTravelTime(22.5)),
+		NewSegment(0, WithSegmentSpeed(36)),
+	}
+	greenWaves := FindGreenWaves(junctions, desiredSpeedKmh, WithSegments(segments))
+	correctDistances := []float64{200, 400, 150}
+	correctTravelTimes := []float64{18, 22.5, 15}
+	assert.Equalf(t, len(correctDistances), len(greenWaves), "Expected %d segments, got %d", len(correctDistances), len(greenWaves))
+	for i, segmentGreenWaves := range greenWaves {
+		for j, greenWave := range segmentGreenWaves {
+			assert.InDeltaf(t, correctDistances[i], greenWave.Distance(), 1e-9, "Segment %d, Green Wave %d: distance mismatch", i, j)
+			assert.InDeltaf(t, correctTravelTimes[i], greenWave.TravelTime(), 1e-9, "Segment %d, Green Wave %d: travel time mismatch", i, j)
+		}
+	}
+
+	// Inbound direction uses the same segments in reversed order
+	inboundGreenWaves := FindGreenWavesInbound(junctions, desiredSpeedKmh, WithSegments(segments))
+	for i, segmentGreenWaves := range inboundGreenWaves {
+		outboundIdx := len(correctDistances) - 1 - i
+		for j, greenWave := range segmentGreenWaves {
+			assert.InDeltaf(t, correctDistances[outboundIdx], greenWave.Distance(), 1e-9, "Inbound segment %d, Green Wave %d: distance mismatch", i, j)
+			assert.InDeltaf(t, correctTravelTimes[outboundIdx], greenWave.TravelTime(), 1e-9, "Inbound segment %d, Green Wave %d: travel time mismatch", i, j)
+		}
+	}
+}
+
+func TestFindGreenWavesWithGeometry(t *testing.T) {
+	junctions := basicTestJuntions()
+	desiredSpeedKmh := 40.0
+	// Curved road between the first and the second junctions: 100 + 150 + 50 = 300 meters
+	geometry := []Point{{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 150}, {X: 100, Y: 200}}
+	segments := []*Segment{
+		NewSegment(0, WithSegmentGeometry(geometry)),
+		nil,
+		nil,
+	}
+	assert.InDelta(t, 300.0, PolylineLength(geometry), 1e-9, "Polyline length mismatch")
+
+	greenWaves := FindGreenWaves(junctions, desiredSpeedKmh, WithSegments(segments))
+	for j, greenWave := range greenWaves[0] {
+		assert.InDeltaf(t, 300.0, greenWave.Distance(), 1e-9, "Green Wave %d: distance mismatch", j)
+		assert.InDeltaf(t, 27.0, greenWave.TravelTime(), 1e-9, "Green Wave %d: travel time mismatch", j)
+		assert.Equalf(t, geometry, greenWave.Geometry(), "Green Wave %d: geometry mismatch", j)
+	}
+	for j, greenWave := range greenWaves[1] {
+		assert.Nilf(t, greenWave.Geometry(), "Green Wave %d: expected no geometry", j)
+	}
+
+	// Inbound direction gets reversed geometry on the last segment
+	inboundGreenWaves := FindGreenWavesInbound(junctions, desiredSpeedKmh, WithSegments(segments))
+	reversedGeometry := []Point{{X: 100, Y: 200}, {X: 100, Y: 150}, {X: 100, Y: 0}, {X: 0, Y: 0}}
+	lastSegment := inboundGreenWaves[len(inboundGreenWaves)-1]
+	for j, greenWave := range lastSegment {
+		assert.Equalf(t, reversedGeometry, greenWave.Geometry(), "Inbound Green Wave %d: geometry mismatch", j)
+	}
+}
@@ -5,20 +5,21 @@ import (
 )
 
 // Signal represents a traffic light signal with its duration and color.
+// Durations are fractional seconds, so controllers working in tenths of a second (or finer) are supported.
 // Also it includes minimum and maximum duration threshold for the signal which could be usefull during optimizations of traffic light timings.
 type Signal struct {
 	// Duration is the duration of the signal in seconds.
-	Duration int
+	Duration float64
 	// MinDuration is the minimum duration for the signal in seconds. Could be used during optimizations in further researchs.
-	MinDuration int
+	MinDuration float64
 	// MaxDuration is the maximum duration for the signal in seconds. Could be used during optimizations in further researchs.
-	MaxDuration int
+	MaxDuration float64
 	// Color is the color of the signal
 	Color color.Color
 }
 
 // NewSignal creates a new Signal instance with the specified duration, minimum and maximum durations, and color.
-func NewSignal(duration int, c color.Color, options ...func(*Signal)) *Signal {
+func NewSignal(duration float64, c color.Color, options ...func(*Signal)) *Signal {
 	signal := &Signal{
 		Duration:    duration,
 		MinDuration: duration,
@@ -32,14 +33,14 @@ func NewSignal(duration int, c color.Color, options ...func(*Signal)) *Signal {
 }
 
 // WithMinDuration is an option function that sets the minimum duration for the signal.
-func WithMinDuration(minDuration int) func(*Signal) {
+func WithMinDuration(minDuration float64) func(*Signal) {
 	return func(s *Signal) {
 		s.MinDuration = minDuration
 	}
 }
 
 // WithMaxDuration is an option function that sets the maximum duration for the signal.
-func WithMaxDuration(maxDuration int) func(*Signal) {
+func WithMaxDuration(maxDuration float64) func(*Signal) {
 	return func(s *Signal) {
 		s.MaxDuration = maxDuration
 	}
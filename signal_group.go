@@ -0,0 +1,29 @@
+package greenwave
+
+// SignalGroup represents a named group of signals (e.g. "NB through" or "SB left") with its own color timeline across the cycle.
+type SignalGroup struct {
+	// Name of the signal group
+	Name string
+	// Signals define the color timeline of the group from the beginning of the cycle. Total duration should match the junction cycle
+	Signals []*Signal
+	// Total duration of the timeline in seconds, calculated from the signals
+	totalSeconds float64
+}
+
+// NewSignalGroup creates a new SignalGroup instance with the specified name and color timeline.
+func NewSignalGroup(name string, signals []*Signal) *SignalGroup {
+	totalSeconds := 0.0
+	for _, signal := range signals {
+		totalSeconds += signal.Duration
+	}
+	return &SignalGroup{
+		Name:         name,
+		Signals:      signals,
+		totalSeconds: totalSeconds,
+	}
+}
+
+// GetTotalSeconds returns the total duration of the signal group timeline in seconds.
+func (sg *SignalGroup) GetTotalSeconds() float64 {
+	return sg.totalSeconds
+}
@@ -0,0 +1,93 @@
+package greenwave
+
+import (
+	"testing"
+
+	"github.com/LdDl/greenwave/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func signalGroupsTestJunction(options ...func(*Junction)) *Junction {
+	// Phases timeline: both phases contain green for some movement
+	cycle := []*Phase{
+		NewPhase(0, []*Signal{
+			NewSignal(30, color.GREEN),
+		}),
+		NewPhase(1, []*Signal{
+			NewSignal(30, color.GREEN),
+		}),
+	}
+	groups := WithSignalGroups(
+		NewSignalGroup("NB through", []*Signal{
+			NewSignal(25, color.GREEN),
+			NewSignal(3, color.YELLOW),
+			NewSignal(32, color.RED),
+		}),
+		NewSignalGroup("SB through", []*Signal{
+			NewSignal(10, color.GREEN),
+			NewSignal(40, color.RED),
+			NewSignal(10, color.GREEN),
+		}),
+		NewSignalGroup("EB through", []*Signal{
+			NewSignal(30, color.RED),
+			NewSignal(27, color.GREEN),
+			NewSignal(3, color.YELLOW),
+		}),
+	)
+	return NewJunction(cycle, append(options, groups)...)
+}
+
+func TestSignalGroupGreenIntervals(t *testing.T) {
+	junction := signalGroupsTestJunction()
+
+	// Phases timeline considers every green
+	assert.Equal(t, 2, len(junction.GetGreenIntervals()), "Expected intervals for both phases")
+
+	greenIntervals := junction.GetGreenIntervals("NB through")
+	assert.Equal(t, 1, len(greenIntervals), "Expected single interval for NB through")
+	assert.Equal(t, 0, greenIntervals[0].PhaseIdx, "Phase index mismatch")
+	assert.InDelta(t, 0.0, greenIntervals[0].Start, 1e-9, "Start mismatch")
+	assert.InDelta(t, 25.0, greenIntervals[0].End, 1e-9, "End mismatch")
+
+	greenIntervals = junction.GetGreenIntervals("EB through")
+	assert.Equal(t, 1, len(greenIntervals), "Expected single interval for EB through")
+	assert.Equal(t, 1, greenIntervals[0].PhaseIdx, "Phase index mismatch")
+	assert.InDelta(t, 30.0, greenIntervals[0].Start, 1e-9, "Start mismatch")
+	assert.InDelta(t, 57.0, greenIntervals[0].End, 1e-9, "End mismatch")
+
+	// Green through the cycle end is a single interval
+	greenIntervals = junction.GetGreenIntervals("SB through")
+	assert.Equal(t, 1, len(greenIntervals), "Expected single interval for SB through")
+	assert.Equal(t, 1, greenIntervals[0].PhaseIdx, "Phase index mismatch")
+	assert.InDelta(t, 50.0, greenIntervals[0].Start, 1e-9, "Start mismatch")
+	assert.InDelta(t, 70.0, greenIntervals[0].End, 1e-9, "End mismatch")
+
+	// Effective green policy applies to signal groups too
+	junction = signalGroupsTestJunction(WithEffectiveGreen(NewEffectiveGreen(2, 2)))
+	greenIntervals = junction.GetGreenIntervals("NB through")
+	assert.InDelta(t, 2.0, greenIntervals[0].Start, 1e-9, "Start mismatch")
+	assert.InDelta(t, 27.0, greenIntervals[0].End, 1e-9, "End mismatch")
+
+	// Unknown group has no green
+	assert.Equal(t, 0, len(junction.GetGreenIntervals("WB left")), "Expected no intervals for unknown group")
+}
+
+func TestFindGreenWavesSignalGroups(t *testing.T) {
+	junctions := []*Junction{
+		signalGroupsTestJunction(WithPoint(Point{X: 0, Y: 0})),
+		signalGroupsTestJunction(WithPoint(Point{X: 0, Y: 50})),
+	}
+	desiredSpeedKmh := 36.0 // 5 seconds per segment
+	groups := WithCoordinatedSignalGroups([]string{"NB through", "NB through"}, []string{"SB through", "SB through"})
+
+	outbound, inbound := FindTwoWayGreenWaves(junctions, desiredSpeedKmh, desiredSpeedKmh, groups)
+	assert.Equal(t, 1, len(outbound[0]), "Expected single outbound wave")
+	assert.InDelta(t, 0.0, outbound[0][0].IntervalJunOne().Start, 1e-9, "Outbound start mismatch")
+	assert.InDelta(t, 20.0, outbound[0][0].IntervalJunOne().End, 1e-9, "Outbound end mismatch")
+
+	// Inbound green (50-70) is split by the cycle end: [50; 60] and [0; 10]
+	for _, wave := range inbound[0] {
+		assert.True(t, wave.IntervalJunOne().Start >= 50 || wave.IntervalJunOne().End <= 10, "Inbound wave should depart within SB through green")
+	}
+	assert.Equal(t, 2, len(inbound[0]), "Expected two inbound waves")
+}
@@ -0,0 +1,320 @@
+package greenwave
+
+import "math"
+
+// constraintKind defines the relation of a linear constraint
+type constraintKind uint8
+
+const (
+	// constraintLE is a "less or equal" constraint
+	constraintLE constraintKind = iota
+	// constraintGE is a "greater or equal" constraint
+	constraintGE
+	// constraintEQ is an equality constraint
+	constraintEQ
+)
+
+// simplexEps is the numerical tolerance of the simplex method
+const simplexEps = 1e-9
+
+// simplexMaxIterations is the safety limit of pivots per simplex phase
+const simplexMaxIterations = 10000
+
+// linearConstraint is a single constraint "coefficients * x (kind) rhs"
+type linearConstraint struct {
+	coefficients []float64
+	kind         constraintKind
+	rhs          float64
+}
+
+// simplexMaximize solves the linear program "maximize objective * x subject to constraints, x >= 0"
+// using the two-phase tableau simplex method with Bland's rule (so it does not cycle).
+// Returns false if the program is infeasible or unbounded.
+func simplexMaximize(objective []float64, constraints []linearConstraint) ([]float64, float64, bool) {
+	varsNum := len(objective)
+	rowsNum := len(constraints)
+	// Count auxiliary columns: slack/surplus for inequalities and artificial for ">=" and "=" rows
+	auxNum, artificialNum := 0, 0
+	normalized := make([]linearConstraint, rowsNum)
+	for i, constraint := range constraints {
+		normalized[i] = constraint
+		if constraint.rhs < 0 {
+			// Keep right hand side non-negative
+			coefficients := make([]float64, len(constraint.coefficients))
+			for j, coefficient := range constraint.coefficients {
+				coefficients[j] = -coefficient
+			}
+			normalized[i] = linearConstraint{coefficients: coefficients, kind: constraint.kind, rhs: -constraint.rhs}
+			switch constraint.kind {
+			case constraintLE:
+				normalized[i].kind = constraintGE
+			case constraintGE:
+				normalized[i].kind = constraintLE
+			}
+		}
+		if normalized[i].kind != constraintEQ {
+			auxNum++
+		}
+		if normalized[i].kind != constraintLE {
+			artificialNum++
+		}
+	}
+	artificialStart := varsNum + auxNum
+	colsNum := artificialStart + artificialNum
+	rhsCol := colsNum
+
+	tableau := make([][]float64, rowsNum+1)
+	for i := range tableau {
+		tableau[i] = make([]float64, colsNum+1)
+	}
+	basis := make([]int, rowsNum)
+	auxCol, artificialCol := varsNum, artificialStart
+	for i, constraint := range normalized {
+		copy(tableau[i], constraint.coefficients)
+		tableau[i][rhsCol] = constraint.rhs
+		switch constraint.kind {
+		case constraintLE:
+			tableau[i][auxCol] = 1
+			basis[i] = auxCol
+			auxCol++
+		case constraintGE:
+			tableau[i][auxCol] = -1
+			auxCol++
+			tableau[i][artificialCol] = 1
+			basis[i] = artificialCol
+			artificialCol++
+		case constraintEQ:
+			tableau[i][artificialCol] = 1
+			basis[i] = artificialCol
+			artificialCol++
+		}
+	}
+
+	objectiveRow := tableau[rowsNum]
+	// Phase 1: maximize minus sum of artificial variables
+	if artificialNum > 0 {
+		for j := artificialStart; j < colsNum; j++ {
+			objectiveRow[j] = 1
+		}
+		for i := 0; i < rowsNum; i++ {
+			if basis[i] >= artificialStart {
+				for j := range objectiveRow {
+					objectiveRow[j] -= tableau[i][j]
+				}
+			}
+		}
+		if !simplexIterate(tableau, basis, colsNum) {
+			return nil, 0, false
+		}
+		if objectiveRow[rhsCol] < -1e-7 {
+			return nil, 0, false // Infeasible
+		}
+		// Drive artificial variables out of the basis where it is possible
+		for i := 0; i < rowsNum; i++ {
+			if basis[i] < artificialStart {
+				continue
+			}
+			for j := 0; j < artificialStart; j++ {
+				if math.Abs(tableau[i][j]) > simplexEps {
+					simplexPivot(tableau, basis, i, j)
+					break
+				}
+			}
+		}
+	}
+
+	// Phase 2: original objective over non-artificial columns
+	for j := range objectiveRow {
+		objectiveRow[j] = 0
+	}
+	for j := 0; j < varsNum; j++ {
+		objectiveRow[j] = -objective[j]
+	}
+	for i := 0; i < rowsNum; i++ {
+		if coefficient := objectiveRow[basis[i]]; coefficient != 0 {
+			for j := range objectiveRow {
+				objectiveRow[j] -= coefficient * tableau[i][j]
+			}
+		}
+	}
+	if !simplexIterate(tableau, basis, artificialStart) {
+		return nil, 0, false
+	}
+
+	solution := make([]float64, varsNum)
+	for i := 0; i < rowsNum; i++ {
+		if basis[i] < varsNum {
+			solution[basis[i]] = tableau[i][rhsCol]
+		}
+	}
+	value := 0.0
+	for j := 0; j < varsNum; j++ {
+		value += objective[j] * solution[j]
+	}
+	return solution, value, true
+}
+
+// simplexIterate pivots the tableau until optimality considering columns [0; allowedCols) as entering candidates.
+// Returns false if the program is unbounded or iterations limit has been reached.
+func simplexIterate(tableau [][]float64, basis []int, allowedCols int) bool {
+	rowsNum := len(basis)
+	objectiveRow := tableau[rowsNum]
+	rhsCol := len(objectiveRow) - 1
+	for iteration := 0; iteration < simplexMaxIterations; iteration++ {
+		// Bland's rule: the first column with negative reduced cost enters
+		entering := -1
+		for j := 0; j < allowedCols; j++ {
+			if objectiveRow[j] < -simplexEps {
+				entering = j
+				break
+			}
+		}
+		if entering < 0 {
+			return true // Optimal
+		}
+		leaving := -1
+		bestRatio := math.Inf(1)
+		for i := 0; i < rowsNum; i++ {
+			if tableau[i][entering] <= simplexEps {
+				continue
+			}
+			ratio := tableau[i][rhsCol] / tableau[i][entering]
+			if ratio < bestRatio-simplexEps || (leaving >= 0 && math.Abs(ratio-bestRatio) <= simplexEps && basis[i] < basis[leaving]) {
+				bestRatio = ratio
+				leaving = i
+			}
+		}
+		if leaving < 0 {
+			return false // Unbounded
+		}
+		simplexPivot(tableau, basis, leaving, entering)
+	}
+	return false
+}
+
+// simplexPivot makes the column basic in the given row.
+func simplexPivot(tableau [][]float64, basis []int, row, col int) {
+	pivotRow := tableau[row]
+	pivot := pivotRow[col]
+	for j := range pivotRow {
+		pivotRow[j] /= pivot
+	}
+	for i, currentRow := range tableau {
+		if i == row {
+			continue
+		}
+		factor := currentRow[col]
+		if factor == 0 {
+			continue
+		}
+		for j := range currentRow {
+			currentRow[j] -= factor * pivotRow[j]
+		}
+	}
+	basis[row] = col
+}
+
+// mixedIntegerProgram is the program "maximize objective * x subject to constraints and lower <= x <= upper"
+// where some variables must be integer.
+type mixedIntegerProgram struct {
+	objective   []float64
+	constraints []linearConstraint
+	// lower contains finite lower bounds of variables
+	lower []float64
+	// upper contains upper bounds of variables (could be +Inf)
+	upper []float64
+	// integer marks variables which must be integer
+	integer []bool
+	// maxNodes is the limit of explored nodes. Non-positive value means branchAndBoundMaxNodes
+	maxNodes int
+	// truncated is true if the last solve call has reached the node limit, so the returned solution might be not optimal
+	truncated bool
+}
+
+// branchAndBoundMaxNodes is the default safety limit of explored nodes. The best found solution is returned if it is reached
+const branchAndBoundMaxNodes = 100000
+
+// solve solves the program by depth-first branch-and-bound over the linear relaxation.
+// Returns false if there is no feasible solution (or none has been found within the node limit, see truncated).
+func (program *mixedIntegerProgram) solve() ([]float64, float64, bool) {
+	maxNodes := program.maxNodes
+	if maxNodes <= 0 {
+		maxNodes = branchAndBoundMaxNodes
+	}
+	type node struct {
+		lower []float64
+		upper []float64
+	}
+	var bestSolution []float64
+	bestValue := math.Inf(-1)
+	stack := []node{{lower: program.lower, upper: program.upper}}
+	for explored := 0; len(stack) > 0 && explored < maxNodes; explored++ {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		solution, value, ok := program.relaxation(current.lower, current.upper)
+		if !ok || value <= bestValue+simplexEps {
+			continue // Infeasible or could not improve the incumbent
+		}
+		branchVar := -1
+		for j, isInteger := range program.integer {
+			if isInteger && math.Abs(solution[j]-math.Round(solution[j])) > 1e-6 {
+				branchVar = j
+				break
+			}
+		}
+		if branchVar < 0 {
+			for j, isInteger := range program.integer {
+				if isInteger {
+					solution[j] = math.Round(solution[j])
+				}
+			}
+			bestSolution, bestValue = solution, value
+			continue
+		}
+		down := node{lower: current.lower, upper: append([]float64(nil), current.upper...)}
+		down.upper[branchVar] = math.Floor(solution[branchVar])
+		up := node{lower: append([]float64(nil), current.lower...), upper: current.upper}
+		up.lower[branchVar] = math.Ceil(solution[branchVar])
+		stack = append(stack, down, up)
+	}
+	program.truncated = len(stack) > 0
+	if bestSolution == nil {
+		return nil, 0, false
+	}
+	return bestSolution, bestValue, true
+}
+
+// relaxation solves the linear relaxation of the program within the given bounds.
+func (program *mixedIntegerProgram) relaxation(lower, upper []float64) ([]float64, float64, bool) {
+	varsNum := len(program.objective)
+	constraints := make([]linearConstraint, 0, len(program.constraints)+varsNum)
+	// Shift variables by their lower bounds: x = lower + y, y >= 0
+	for _, constraint := range program.constraints {
+		rhs := constraint.rhs
+		for j, coefficient := range constraint.coefficients {
+			rhs -= coefficient * lower[j]
+		}
+		constraints = append(constraints, linearConstraint{coefficients: constraint.coefficients, kind: constraint.kind, rhs: rhs})
+	}
+	for j := 0; j < varsNum; j++ {
+		if upper[j] < lower[j]-simplexEps {
+			return nil, 0, false
+		}
+		if math.IsInf(upper[j], 1) {
+			continue
+		}
+		coefficients := make([]float64, varsNum)
+		coefficients[j] = 1
+		constraints = append(constraints, linearConstraint{coefficients: coefficients, kind: constraintLE, rhs: upper[j] - lower[j]})
+	}
+	shifted, value, ok := simplexMaximize(program.objective, constraints)
+	if !ok {
+		return nil, 0, false
+	}
+	solution := make([]float64, varsNum)
+	for j := range solution {
+		solution[j] = shifted[j] + lower[j]
+		value += program.objective[j] * lower[j]
+	}
+	return solution, value, true
+}
@@ -10,6 +10,9 @@ type ThroughGreenWave struct {
 	depth int
 	// Bandwidth of the green wave, which is the minimum duration of the green intervals
 	bandWidth float64
+	// Bandwidth of the green wave which remains valid for every speed within the speed range.
+	// Equals to bandWidth if speed range has not been provided
+	robustBandWidth float64
 }
 
 // NewThroughGreenWave creates a new ThroughGreenWave from a slice of GreenInterval.
@@ -26,9 +29,10 @@ func NewThroughGreenWave(intervals []*GreenInterval) *ThroughGreenWave {
 		minBandWidth = 0
 	}
 	return &ThroughGreenWave{
-		intervals: intervals,
-		depth:     len(intervals),
-		bandWidth: minBandWidth,
+		intervals:       intervals,
+		depth:           len(intervals),
+		bandWidth:       minBandWidth,
+		robustBandWidth: minBandWidth,
 	}
 }
 
@@ -42,6 +46,12 @@ func (tgw *ThroughGreenWave) Bandwidth() float64 {
 	return tgw.bandWidth
 }
 
+// RobustBandwidth returns the bandwidth of the green wave which remains valid for every speed within the speed range (see WithSpeedRange).
+// Equals to Bandwidth if speed range has not been provided.
+func (tgw *ThroughGreenWave) RobustBandwidth() float64 {
+	return tgw.robustBandWidth
+}
+
 // GetIntervals returns the intervals of the green wave.
 func (tgw *ThroughGreenWave) GetIntervals() []*GreenInterval {
 	return tgw.intervals